@@ -0,0 +1,160 @@
+// Доступ к закрытым эндпоинтам: заявки на access: gated (пользователь
+// запрашивает, владелец одобряет или отклоняет) и allow-list для
+// access: private.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Статусы заявки на доступ.
+const (
+	AccessPending  = "pending"
+	AccessApproved = "approved"
+	AccessDenied   = "denied"
+	AccessRevoked  = "revoked"
+)
+
+// AccessRequest — заявка на доступ к эндпоинту с access: gated.
+type AccessRequest struct {
+	ID             int64      `json:"id"`
+	Plugin         string     `json:"plugin"`
+	Endpoint       string     `json:"endpoint"`
+	UserID         int64      `json:"user_id"`
+	Reason         string     `json:"reason,omitempty"`
+	Status         string     `json:"status"`                    // AccessPending, AccessApproved, ...
+	DecisionReason string     `json:"decision_reason,omitempty"` // причина отказа или отзыва
+	CreatedAt      string     `json:"created_at"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+}
+
+// AllowEntry — запись allow-list эндпоинтов с access: private.
+// Задаётся UserID или ChatID.
+type AllowEntry struct {
+	ID        int64  `json:"id,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"` // пусто — все private-эндпоинты плагина
+	UserID    int64  `json:"user_id,omitempty"`
+	ChatID    int64  `json:"chat_id,omitempty"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// ── Пользователь ────────────────────────────────────────────────────────
+
+// RequestAccess отправляет владельцу плагина заявку на доступ к эндпоинту.
+// Повторная заявка, пока предыдущая не рассмотрена, — ErrConflict.
+func (c *Client) RequestAccess(plugin, endpoint, reason string) (*AccessRequest, error) {
+	if plugin == "" || endpoint == "" {
+		return nil, fmt.Errorf("integrat: plugin and endpoint are required")
+	}
+	body := map[string]string{"plugin": plugin, "endpoint": endpoint, "reason": reason}
+	respBody, _, err := c.doJSON(context.Background(), "POST", "/v1/access/requests", body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccessRequest(respBody)
+}
+
+// GetAccessStatus возвращает последнюю заявку текущего пользователя на
+// доступ к эндпоинту. Если заявок не было — ErrNotFound.
+func (c *Client) GetAccessStatus(plugin, endpoint string) (*AccessRequest, error) {
+	v := url.Values{"plugin": {plugin}, "endpoint": {endpoint}}
+	respBody, _, err := c.doRequest(context.Background(), "GET", "/v1/access/requests/status?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccessRequest(respBody)
+}
+
+// ── Владелец плагина ────────────────────────────────────────────────────
+
+// ListAccessRequests возвращает заявки на доступ к плагину. Пустой
+// status — все заявки.
+func (c *Client) ListAccessRequests(pluginID int64, status string) ([]AccessRequest, error) {
+	path := fmt.Sprintf("/v1/plugins/%d/access-requests", pluginID)
+	if status != "" {
+		path += "?" + url.Values{"status": {status}}.Encode()
+	}
+	respBody, _, err := c.doRequest(context.Background(), "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []AccessRequest
+	if err := json.Unmarshal(respBody, &reqs); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return reqs, nil
+}
+
+// ApproveAccess одобряет заявку.
+func (c *Client) ApproveAccess(pluginID, requestID int64) (*AccessRequest, error) {
+	return c.decideAccess(pluginID, requestID, "approve", "")
+}
+
+// DenyAccess отклоняет заявку; reason увидит пользователь.
+func (c *Client) DenyAccess(pluginID, requestID int64, reason string) (*AccessRequest, error) {
+	return c.decideAccess(pluginID, requestID, "deny", reason)
+}
+
+// RevokeAccess отзывает ранее выданный доступ.
+func (c *Client) RevokeAccess(pluginID, requestID int64, reason string) (*AccessRequest, error) {
+	return c.decideAccess(pluginID, requestID, "revoke", reason)
+}
+
+func (c *Client) decideAccess(pluginID, requestID int64, action, reason string) (*AccessRequest, error) {
+	path := fmt.Sprintf("/v1/plugins/%d/access-requests/%d/%s", pluginID, requestID, action)
+	respBody, _, err := c.doJSON(context.Background(), "POST", path, map[string]string{"reason": reason})
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccessRequest(respBody)
+}
+
+func decodeAccessRequest(data []byte) (*AccessRequest, error) {
+	var ar AccessRequest
+	if err := json.Unmarshal(data, &ar); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &ar, nil
+}
+
+// ── Allow-list ──────────────────────────────────────────────────────────
+
+// ListAllowList возвращает allow-list плагина.
+func (c *Client) ListAllowList(pluginID int64) ([]AllowEntry, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/plugins/%d/allowlist", pluginID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries []AllowEntry
+	if err := json.Unmarshal(respBody, &entries); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return entries, nil
+}
+
+// AddToAllowList добавляет пользователя или чат в allow-list.
+func (c *Client) AddToAllowList(pluginID int64, entry AllowEntry) (*AllowEntry, error) {
+	if (entry.UserID == 0) == (entry.ChatID == 0) {
+		return nil, fmt.Errorf("integrat: allow-list entry needs exactly one of user_id and chat_id")
+	}
+	respBody, _, err := c.doJSON(context.Background(), "POST", fmt.Sprintf("/v1/plugins/%d/allowlist", pluginID), entry)
+	if err != nil {
+		return nil, err
+	}
+	var e AllowEntry
+	if err := json.Unmarshal(respBody, &e); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &e, nil
+}
+
+// RemoveFromAllowList удаляет запись из allow-list.
+func (c *Client) RemoveFromAllowList(pluginID, entryID int64) error {
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/plugins/%d/allowlist/%d", pluginID, entryID), nil)
+	return err
+}
@@ -0,0 +1,130 @@
+package integrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestAccess(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/access/requests":
+			json.NewDecoder(r.Body).Decode(&got)
+			io.WriteString(w, `{"id":4,"plugin":"channel-mcp","endpoint":"messages.search","status":"pending"}`)
+		case "GET /v1/access/requests/status":
+			if r.URL.Query().Get("endpoint") != "messages.search" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"no access requests"}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	ar, err := c.RequestAccess("channel-mcp", "messages.search", "исследование")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["reason"] != "исследование" || ar.Status != AccessPending {
+		t.Errorf("request = %v, response = %+v", got, ar)
+	}
+
+	if _, err := c.GetAccessStatus("channel-mcp", "messages.search"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("status err = %v, want ErrNotFound", err)
+	}
+	if _, err := c.RequestAccess("channel-mcp", "", ""); err == nil {
+		t.Error("expected error without endpoint")
+	}
+}
+
+func TestDecideAccess(t *testing.T) {
+	var paths []string
+	var reasons []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.String())
+		if r.Method == "GET" {
+			io.WriteString(w, `[{"id":4,"status":"pending"}]`)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		reasons = append(reasons, body["reason"])
+		io.WriteString(w, `{"id":4,"status":"approved"}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	reqs, err := c.ListAccessRequests(2, AccessPending)
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("list = %+v, %v", reqs, err)
+	}
+	if _, err := c.ApproveAccess(2, 4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DenyAccess(2, 4, "спам"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RevokeAccess(2, 4, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /v1/plugins/2/access-requests?status=pending",
+		"POST /v1/plugins/2/access-requests/4/approve",
+		"POST /v1/plugins/2/access-requests/4/deny",
+		"POST /v1/plugins/2/access-requests/4/revoke",
+	}
+	for i := range want {
+		if i >= len(paths) || paths[i] != want[i] {
+			t.Errorf("calls = %v, want %v", paths, want)
+			break
+		}
+	}
+	if len(reasons) != 3 || reasons[1] != "спам" {
+		t.Errorf("reasons = %q", reasons)
+	}
+}
+
+func TestAllowList(t *testing.T) {
+	var added AllowEntry
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			io.WriteString(w, `[{"id":1,"endpoint":"admin.stats","user_id":7}]`)
+		case "POST":
+			json.NewDecoder(r.Body).Decode(&added)
+			io.WriteString(w, `{"id":2,"chat_id":-100500}`)
+		case "DELETE":
+			deleted = r.URL.Path
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	entries, err := c.ListAllowList(2)
+	if err != nil || len(entries) != 1 || entries[0].UserID != 7 {
+		t.Errorf("list = %+v, %v", entries, err)
+	}
+	e, err := c.AddToAllowList(2, AllowEntry{ChatID: -100500, Note: "команда"})
+	if err != nil || e.ID != 2 || added.ChatID != -100500 {
+		t.Errorf("add = %+v (sent %+v), %v", e, added, err)
+	}
+	if err := c.RemoveFromAllowList(2, 2); err != nil || deleted != "/v1/plugins/2/allowlist/2" {
+		t.Errorf("remove: %s, %v", deleted, err)
+	}
+
+	for _, bad := range []AllowEntry{{}, {UserID: 1, ChatID: 2}} {
+		if _, err := c.AddToAllowList(2, bad); err == nil {
+			t.Errorf("AddToAllowList(%+v): expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Подписки и покупки в маркетплейсе: планы плагина (pricing.plans),
+// оформление подписки или разовой покупки за Telegram Stars и проверка
+// доступных прав (entitlements).
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Периоды тарифного плана.
+const (
+	PlanPeriodMonth = "month" // подписка на месяц
+	PlanPeriodYear  = "year"  // подписка на год
+	PlanPeriodOnce  = "once"  // разовая покупка
+)
+
+// Статусы подписки и покупки.
+const (
+	SubscriptionStatusPending  = "pending"  // ожидает оплаты по InvoiceLink
+	SubscriptionStatusActive   = "active"   // оплачена и действует
+	SubscriptionStatusCanceled = "canceled" // отменена, действует до CurrentPeriodEnd
+	SubscriptionStatusExpired  = "expired"  // истекла
+)
+
+// Источники прав доступа (Entitlement.Source).
+const (
+	UsageSourceFree         = "free"         // free_tier
+	UsageSourceSubscription = "subscription" // подписка
+	UsageSourcePurchase     = "purchase"     // разовая покупка
+	UsageSourceAccess       = "access"       // доступ, выданный владельцем
+)
+
+// Plan — тарифный план плагина (pricing.plans в integrat.yaml).
+type Plan struct {
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Price       int64    `json:"price"`              // Stars за период
+	Period      string   `json:"period"`             // PlanPeriodMonth, PlanPeriodYear, PlanPeriodOnce
+	Included    int64    `json:"included,omitempty"` // запросов в период; 0 — без ограничения
+	Endpoints   []string `json:"endpoints,omitempty"`
+}
+
+// Subscription — подписка на план плагина.
+type Subscription struct {
+	ID                int64     `json:"id"`
+	Plugin            string    `json:"plugin"`
+	Plan              Plan      `json:"plan"`
+	ChatID            int64     `json:"chat_id,omitempty"` // 0 — личная подписка
+	Status            string    `json:"status"`
+	InvoiceLink       string    `json:"invoice_link,omitempty"` // ссылка на оплату в Telegram (SubscriptionStatusPending)
+	CurrentPeriodEnd  time.Time `json:"current_period_end"`
+	CancelAtPeriodEnd bool      `json:"cancel_at_period_end,omitempty"`
+	CreatedAt         string    `json:"created_at"`
+}
+
+// Purchase — разовая покупка плана.
+type Purchase struct {
+	ID          int64  `json:"id"`
+	Plugin      string `json:"plugin"`
+	Plan        Plan   `json:"plan"`
+	ChatID      int64  `json:"chat_id,omitempty"`
+	Amount      int64  `json:"amount"` // Stars
+	Status      string `json:"status"`
+	InvoiceLink string `json:"invoice_link,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Entitlement — право на запросы к плагину.
+type Entitlement struct {
+	Plugin    string     `json:"plugin"`
+	Endpoints []string   `json:"endpoints,omitempty"` // пусто — все эндпоинты
+	Source    string     `json:"source"`              // UsageSourceFree, UsageSourceSubscription, ...
+	Plan      string     `json:"plan,omitempty"`
+	ChatID    int64      `json:"chat_id,omitempty"`
+	Remaining *int64     `json:"remaining,omitempty"` // nil — без ограничения
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Entitlements — права пользователя на плагин.
+type Entitlements []Entitlement
+
+// Covers сообщает, есть ли на момент now право на запрос к эндпоинту.
+func (es Entitlements) Covers(endpoint string, now time.Time) bool {
+	for _, e := range es {
+		if e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+			continue
+		}
+		if e.Remaining != nil && *e.Remaining <= 0 {
+			continue
+		}
+		if len(e.Endpoints) == 0 {
+			return true
+		}
+		for _, ep := range e.Endpoints {
+			if ep == endpoint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SubscribeParams — параметры оформления подписки или покупки.
+type SubscribeParams struct {
+	Plugin string `json:"plugin"`            // slug плагина
+	Plan   string `json:"plan"`              // slug плана
+	ChatID int64  `json:"chat_id,omitempty"` // подписка для чата; 0 — личная
+}
+
+func (p SubscribeParams) check() error {
+	if p.Plugin == "" {
+		return fmt.Errorf("integrat: plugin is required")
+	}
+	if p.Plan == "" {
+		return fmt.Errorf("integrat: plan is required")
+	}
+	return nil
+}
+
+// ListSubscriptions возвращает подписки текущего пользователя.
+func (c *Client) ListSubscriptions() ([]Subscription, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", "/v1/subscriptions", nil)
+	if err != nil {
+		return nil, err
+	}
+	var subs []Subscription
+	if err := json.Unmarshal(respBody, &subs); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return subs, nil
+}
+
+// Subscribe оформляет подписку на план. Подписка создаётся в статусе
+// SubscriptionStatusPending — пользователь оплачивает её по InvoiceLink.
+// Повторная подписка на тот же план — ErrConflict.
+func (c *Client) Subscribe(params SubscribeParams) (*Subscription, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	respBody, _, err := c.doJSON(context.Background(), "POST", "/v1/subscriptions", params)
+	if err != nil {
+		return nil, err
+	}
+	var sub Subscription
+	if err := json.Unmarshal(respBody, &sub); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &sub, nil
+}
+
+// CancelSubscription отменяет подписку: она действует до конца
+// оплаченного периода и не продлевается.
+func (c *Client) CancelSubscription(id int64) (*Subscription, error) {
+	respBody, _, err := c.doRequest(context.Background(), "POST", fmt.Sprintf("/v1/subscriptions/%d/cancel", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var sub Subscription
+	if err := json.Unmarshal(respBody, &sub); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &sub, nil
+}
+
+// Purchase оформляет разовую покупку плана с period: once.
+func (c *Client) Purchase(params SubscribeParams) (*Purchase, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	respBody, _, err := c.doJSON(context.Background(), "POST", "/v1/purchases", params)
+	if err != nil {
+		return nil, err
+	}
+	var p Purchase
+	if err := json.Unmarshal(respBody, &p); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &p, nil
+}
+
+// GetEntitlements возвращает права текущего пользователя на плагин.
+// chatID != 0 добавляет права, оформленные на чат.
+func (c *Client) GetEntitlements(plugin string, chatID int64) (Entitlements, error) {
+	v := url.Values{"plugin": {plugin}}
+	if chatID != 0 {
+		v.Set("chat_id", fmt.Sprint(chatID))
+	}
+	respBody, _, err := c.doRequest(context.Background(), "GET", "/v1/entitlements?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var es Entitlements
+	if err := json.Unmarshal(respBody, &es); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return es, nil
+}
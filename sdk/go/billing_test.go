@@ -0,0 +1,126 @@
+package integrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribeAndCancel(t *testing.T) {
+	var got SubscribeParams
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/subscriptions":
+			json.NewDecoder(r.Body).Decode(&got)
+			io.WriteString(w, `{"id":9,"plugin":"channel-mcp","plan":{"slug":"pro","name":"Pro","price":500,"period":"month","included":10000},
+				"status":"pending","invoice_link":"https://t.me/$abc","current_period_end":"2026-11-18T00:00:00Z"}`)
+		case "POST /v1/subscriptions/9/cancel":
+			io.WriteString(w, `{"id":9,"plugin":"channel-mcp","plan":{"slug":"pro"},"status":"canceled","cancel_at_period_end":true}`)
+		case "GET /v1/subscriptions":
+			io.WriteString(w, `[{"id":9,"plugin":"channel-mcp","plan":{"slug":"pro"},"status":"active"}]`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	sub, err := c.Subscribe(SubscribeParams{Plugin: "channel-mcp", Plan: "pro", ChatID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Plugin != "channel-mcp" || got.Plan != "pro" || got.ChatID != 42 {
+		t.Errorf("request = %+v", got)
+	}
+	if sub.Status != SubscriptionStatusPending || sub.InvoiceLink == "" || sub.Plan.Price != 500 || sub.Plan.Period != PlanPeriodMonth {
+		t.Errorf("subscription = %+v", sub)
+	}
+
+	sub, err = c.CancelSubscription(9)
+	if err != nil || sub.Status != SubscriptionStatusCanceled || !sub.CancelAtPeriodEnd {
+		t.Errorf("cancel = %+v, %v", sub, err)
+	}
+
+	subs, err := c.ListSubscriptions()
+	if err != nil || len(subs) != 1 || subs[0].Status != SubscriptionStatusActive {
+		t.Errorf("list = %+v, %v", subs, err)
+	}
+}
+
+func TestPurchase(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/purchases" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		io.WriteString(w, `{"id":3,"plugin":"p","plan":{"slug":"pack","period":"once"},"amount":100,"status":"pending","invoice_link":"https://t.me/$x"}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	p, err := c.Purchase(SubscribeParams{Plugin: "p", Plan: "pack"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 100 || p.Plan.Period != PlanPeriodOnce {
+		t.Errorf("purchase = %+v", p)
+	}
+
+	for _, params := range []SubscribeParams{{Plan: "x"}, {Plugin: "p"}} {
+		if _, err := c.Purchase(params); err == nil || !strings.Contains(err.Error(), "required") {
+			t.Errorf("Purchase(%+v) err = %v", params, err)
+		}
+	}
+}
+
+func TestSubscribe_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"code":"already_subscribed","message":"already subscribed"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewWithURL("t", srv.URL).Subscribe(SubscribeParams{Plugin: "p", Plan: "pro"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("err = %v, want ErrConflict", err)
+	}
+}
+
+func TestGetEntitlements(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/entitlements" || q.Get("plugin") != "p" || q.Get("chat_id") != "42" {
+			t.Errorf("%s", r.URL)
+		}
+		io.WriteString(w, `[{"plugin":"p","source":"free","remaining":0},
+			{"plugin":"p","source":"purchase","plan":"pack","endpoints":["search"],"remaining":5}]`)
+	}))
+	defer srv.Close()
+
+	es, err := NewWithURL("t", srv.URL).GetEntitlements("p", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if len(es) != 2 || !es.Covers("search", now) || es.Covers("fetch", now) {
+		t.Errorf("entitlements = %+v", es)
+	}
+}
+
+func TestEntitlements_Covers(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	es := Entitlements{{Source: UsageSourceSubscription, ExpiresAt: &past}}
+	if es.Covers("any", now) {
+		t.Error("expired entitlement covers endpoint")
+	}
+	future := now.Add(time.Hour)
+	es = append(es, Entitlement{Source: UsageSourceSubscription, ExpiresAt: &future})
+	if !es.Covers("any", now) {
+		t.Error("active unlimited entitlement does not cover endpoint")
+	}
+}
@@ -0,0 +1,151 @@
+// Клиентский кеш ответов Query — дополняет кеш gateway и экономит
+// сетевые вызовы для повторяющихся запросов в пределах TTL.
+package integrat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache хранит ответы Query по ключу запроса.
+type Cache interface {
+	Get(key string) (*QueryResponse, bool)
+	Set(key string, resp *QueryResponse, ttl time.Duration)
+}
+
+// MemoryCache — потокобезопасный in-memory Cache с ограничением размера.
+// При переполнении вытесняется запись с ближайшим истечением.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	resp    QueryResponse
+	expires time.Time
+}
+
+// NewMemoryCache создаёт кеш на maxEntries записей (<= 0 — 1000).
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]memoryEntry),
+		now:        time.Now,
+	}
+}
+
+// Get возвращает копию ответа, если он есть и не истёк.
+func (m *MemoryCache) Get(key string) (*QueryResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !m.now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return cloneResponse(&e.resp), true
+}
+
+// Set сохраняет копию ответа на ttl.
+func (m *MemoryCache) Set(key string, resp *QueryResponse, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.maxEntries {
+		var victim string
+		var earliest time.Time
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				victim = k
+				break
+			}
+			if victim == "" || e.expires.Before(earliest) {
+				victim, earliest = k, e.expires
+			}
+		}
+		delete(m.entries, victim)
+	}
+	m.entries[key] = memoryEntry{resp: *cloneResponse(resp), expires: now.Add(ttl)}
+}
+
+// cloneResponse копирует ответ вместе с Data: кеш и вызывающие не должны
+// делить одни и те же байты.
+func cloneResponse(resp *QueryResponse) *QueryResponse {
+	c := *resp
+	c.Data = append(json.RawMessage(nil), resp.Data...)
+	return &c
+}
+
+// cacheKey — ключ кеша: BaseURL, область кеша (CacheScope или хеш
+// токена) и тело запроса (plugin, endpoint, chat_id, params). Без области
+// общий Cache отдал бы платные или gated-данные клиенту с другим токеном.
+// json.Marshal сортирует ключи map, поэтому тело детерминировано.
+func (c *Client) cacheKey(ctx context.Context, body []byte) (string, error) {
+	scope := c.CacheScope
+	if scope == "" {
+		token, err := c.token(ctx)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256([]byte(token))
+		scope = "token:" + hex.EncodeToString(sum[:])
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(c.BaseURL), []byte(scope), body} {
+		h.Write([]byte(strconv.Itoa(len(part)) + ":"))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Client) cacheGet(ctx context.Context, call *CallInfo, key string) (*QueryResponse, bool) {
+	if c.Cache == nil {
+		return nil, false
+	}
+	resp, ok := c.Cache.Get(key)
+	if !ok {
+		c.log(ctx, slog.LevelDebug, "integrat cache miss", callAttrs(call)...)
+		return nil, false
+	}
+	c.log(ctx, slog.LevelDebug, "integrat cache hit", callAttrs(call)...)
+	resp = cloneResponse(resp)
+	resp.Cached = true
+	return resp, true
+}
+
+// cacheSet сохраняет ответ на TTL из X-Integrat-TTL (или поля ttl).
+// Устаревшие (stale) ответы не кешируются.
+func (c *Client) cacheSet(ctx context.Context, call *CallInfo, key string, resp *QueryResponse, ttlHeader string) {
+	if c.Cache == nil {
+		return
+	}
+	ttl := resp.TTL
+	if v, err := strconv.Atoi(ttlHeader); err == nil {
+		ttl = v
+	}
+	if resp.Stale || ttl <= 0 {
+		c.log(ctx, slog.LevelDebug, "integrat cache skip", append(callAttrs(call),
+			slog.Bool("stale", resp.Stale), slog.Int("ttl", ttl))...)
+		return
+	}
+	c.Cache.Set(key, cloneResponse(resp), time.Duration(ttl)*time.Second)
+	c.log(ctx, slog.LevelDebug, "integrat cache store", append(callAttrs(call), slog.Int("ttl", ttl))...)
+}
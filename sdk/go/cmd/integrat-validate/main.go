@@ -2,29 +2,47 @@
 //
 // Использование:
 //
-//	integrat-validate [--offline] <file.yaml> [file2.yaml ...]
+//	integrat-validate [--offline] [--log-level=debug] [--log-format=json] <file.yaml> [file2.yaml ...]
 //	integrat-validate ./integrat.yaml
 //	integrat-validate --offline /path/to/integrat.yaml
+//
+// Диагностика пишется в stderr через log/slog; по умолчанию выключена —
+// отчёт в stdout уже содержит все ошибки и предупреждения. Значения
+// секретов (переменных из provider.auth.env) в лог не попадают.
 package main
 
 import (
 	"flag"
 	"fmt"
+	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 
+	integrat "github.com/plagness/Integrat/sdk/go"
 	"github.com/plagness/Integrat/sdk/go/internal/validator"
 )
 
+var logger *slog.Logger
+
 func main() {
 	offline := flag.Bool("offline", false, "Пропустить проверку доступности base_url")
+	logLevel := flag.String("log-level", "off", "Уровень логов: debug, info, warn, error, off")
+	logFormat := flag.String("log-format", "text", "Формат логов: text, json")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Использование: %s [--offline] <file.yaml> [file2.yaml ...]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Использование: %s [--offline] [--log-level=LEVEL] [--log-format=FORMAT] <file.yaml> [file2.yaml ...]\n\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "Валидирует integrat.yaml спецификации плагинов.\n\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
+	var err error
+	logger, err = newLogger(*logLevel, *logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(2)
+	}
+
 	files := flag.Args()
 	if len(files) == 0 {
 		// По умолчанию ищем integrat.yaml в текущей директории
@@ -49,14 +67,40 @@ func main() {
 	}
 }
 
+// levelOff — уровень выше любого используемого: логи не пишутся.
+const levelOff = slog.Level(math.MaxInt32)
+
+// newLogger создаёт slog-логгер в stderr с маскировкой секретов.
+func newLogger(level, format string) (*slog.Logger, error) {
+	lvl := levelOff
+	if level != "off" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("--log-level: недопустимое значение %q (допустимо: debug, info, warn, error, off)", level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: integrat.RedactAttr}
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	default:
+		return nil, fmt.Errorf("--log-format: недопустимое значение %q (допустимо: text, json)", format)
+	}
+}
+
 func validateFile(path string, offline bool) bool {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		logger.Error("read spec", "file", path, "error", err)
 		fmt.Fprintf(os.Stderr, "✗ %s: %v\n", path, err)
 		return false
 	}
+	logger.Debug("validating spec", "file", path, "bytes", len(data))
 
 	spec, result := validator.ValidateBytes(data)
+	logResult(path, spec, result)
 	if spec == nil {
 		// Ошибка парсинга
 		fmt.Printf("✗ %s\n", path)
@@ -114,3 +158,41 @@ func validateFile(path string, offline bool) bool {
 
 	return result.OK()
 }
+
+// logResult пишет в лог итог валидации и сводку по спецификации.
+// Для provider.auth логируется только имя переменной окружения и факт
+// её наличия — значение секрета не читается в лог.
+func logResult(path string, spec *validator.Spec, result *validator.Result) {
+	if spec == nil {
+		logger.Error("parse spec", "file", path, "errors", result.Errors)
+		return
+	}
+
+	logger.Debug("spec parsed",
+		"file", path,
+		"plugin", spec.Plugin.Slug,
+		"version", spec.Plugin.Version,
+		"endpoints", len(spec.Endpoints),
+		"config_fields", len(spec.ConfigFields),
+	)
+	if auth := spec.Provider.Auth; auth != nil {
+		attrs := []any{"file", path, "type", auth.Type}
+		if auth.Env != "" {
+			_, set := os.LookupEnv(auth.Env)
+			attrs = append(attrs, "env", auth.Env, "env_set", set)
+		}
+		logger.Debug("provider auth", attrs...)
+	}
+	for _, w := range result.Warnings {
+		logger.Warn("spec warning", "file", path, "warning", w)
+	}
+	for _, e := range result.Errors {
+		logger.Error("spec error", "file", path, "error", e)
+	}
+	logger.Info("validation finished",
+		"file", path,
+		"ok", result.OK(),
+		"errors", len(result.Errors),
+		"warnings", len(result.Warnings),
+	)
+}
@@ -0,0 +1,71 @@
+// CLI для работы с Integrat API.
+//
+// Использование:
+//
+//	integrat [--base-url URL] <команда> [аргументы]
+//	integrat token create --name ci --scope query --expires 720h
+//	integrat token list
+//	integrat token revoke 42
+//
+// Токен берётся из INTEGRAT_TOKEN, базовый URL — из INTEGRAT_BASE_URL
+// или флага --base-url.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+// command — подкоманда CLI.
+type command struct {
+	name  string
+	usage string
+	run   func(c *integrat.Client, args []string) error
+}
+
+var commands = []command{
+	{name: "token", usage: "управление API-токенами (create, list, revoke)", run: runToken},
+}
+
+func main() {
+	baseURL := flag.String("base-url", "", "Базовый URL API (по умолчанию $INTEGRAT_BASE_URL или "+integrat.DefaultBaseURL+")")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Использование: %s [--base-url URL] <команда> [аргументы]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Команды:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+		}
+		fmt.Fprintf(os.Stderr, "\nТокен берётся из $%s.\n\n", integrat.EnvToken)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var opts []integrat.Option
+	if *baseURL != "" {
+		opts = append(opts, integrat.WithBaseURL(*baseURL))
+	}
+	client := integrat.NewClient(opts...)
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			if err := cmd.run(client, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "✗ неизвестная команда %q\n\n", args[0])
+	flag.Usage()
+	os.Exit(2)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+// scopeList — повторяемый флаг --scope.
+type scopeList []string
+
+func (s *scopeList) String() string { return strings.Join(*s, ",") }
+
+func (s *scopeList) Set(v string) error {
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*s = append(*s, part)
+		}
+	}
+	return nil
+}
+
+func runToken(c *integrat.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("использование: integrat token <create|list|revoke>")
+	}
+	switch args[0] {
+	case "create":
+		return tokenCreate(c, args[1:])
+	case "list":
+		return tokenList(c)
+	case "revoke":
+		return tokenRevoke(c, args[1:])
+	default:
+		return fmt.Errorf("token: неизвестная подкоманда %q (допустимо: create, list, revoke)", args[0])
+	}
+}
+
+func tokenCreate(c *integrat.Client, args []string) error {
+	fs := flag.NewFlagSet("token create", flag.ContinueOnError)
+	name := fs.String("name", "", "Имя токена (обязательно)")
+	expires := fs.Duration("expires", 0, "Срок действия, например 720h (0 — бессрочный)")
+	var scopes scopeList
+	fs.Var(&scopes, "scope", fmt.Sprintf("Скоуп: %s, %s, %s (можно повторять или через запятую)",
+		integrat.ScopeQuery, integrat.ScopePublisher, integrat.ScopeMarketplaceRead))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	params := integrat.CreateTokenParams{Name: *name, Scopes: scopes}
+	if *expires > 0 {
+		at := time.Now().Add(*expires).UTC().Truncate(time.Second)
+		params.ExpiresAt = &at
+	}
+
+	tok, err := c.CreateToken(params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Токен %q создан (id %d, скоупы: %s)\n", tok.Name, tok.ID, strings.Join(tok.Scopes, ", "))
+	if tok.ExpiresAt != nil {
+		fmt.Printf("  истекает: %s\n", tok.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Printf("\n%s\n\n", tok.Token)
+	fmt.Fprintf(os.Stderr, "⚠ Сохраните токен — повторно он показан не будет.\n")
+	return nil
+}
+
+func tokenList(c *integrat.Client) error {
+	tokens, err := c.ListTokens()
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Println("Токенов нет")
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES")
+	for _, t := range tokens {
+		expires := "—"
+		if t.ExpiresAt != nil {
+			expires = t.ExpiresAt.Format(time.RFC3339)
+			if t.Expired(now) {
+				expires += " (истёк)"
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.ID, t.Name, t.Prefix, strings.Join(t.Scopes, ","), expires)
+	}
+	return w.Flush()
+}
+
+func tokenRevoke(c *integrat.Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("использование: integrat token revoke <id>")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("token revoke: невалидный id %q", args[0])
+	}
+	if err := c.RevokeToken(id); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Токен %d отозван\n", id)
+	return nil
+}
@@ -0,0 +1,236 @@
+// Конфигурация плагина: значения полей config_fields на уровне плагина
+// и на уровне чата. Перед сохранением значения проверяются по
+// определениям config_fields плагина.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/plagness/Integrat/sdk/go/internal/validator"
+)
+
+// ConfigField — определение поля конфигурации (элемент Plugin.ConfigFields).
+type ConfigField struct {
+	Slug        string          `json:"slug"`
+	Label       string          `json:"label"`
+	Type        string          `json:"type"`
+	Required    bool            `json:"required,omitempty"`
+	Default     any             `json:"default,omitempty"`
+	Placeholder string          `json:"placeholder,omitempty"`
+	Help        string          `json:"help,omitempty"`
+	Options     []ConfigOption  `json:"options,omitempty"`
+	Min         *float64        `json:"min,omitempty"`         // number, integer
+	Max         *float64        `json:"max,omitempty"`         // number, integer
+	Pattern     string          `json:"pattern,omitempty"`     // string, text, secret, url
+	Schema      json.RawMessage `json:"schema,omitempty"`      // json: JSON Schema значения
+	Group       string          `json:"group,omitempty"`       // секция формы
+	Order       int             `json:"order,omitempty"`       // порядок внутри секции
+	VisibleIf   string          `json:"visible_if,omitempty"`  // условие показа, например "mode == remote"
+	RequiredIf  string          `json:"required_if,omitempty"` // условие обязательности
+}
+
+// ConfigOption — вариант для type: select и multiselect.
+type ConfigOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// ParseConfigFields разбирает Plugin.ConfigFields.
+func (p *Plugin) ParseConfigFields() ([]ConfigField, error) {
+	if len(p.ConfigFields) == 0 || string(p.ConfigFields) == "null" {
+		return nil, nil
+	}
+	var fields []ConfigField
+	if err := json.Unmarshal(p.ConfigFields, &fields); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal config_fields: %w", err)
+	}
+	return fields, nil
+}
+
+// PluginConfig — сохранённые значения конфигурации.
+type PluginConfig struct {
+	PluginID int64          `json:"plugin_id"`
+	ChatID   int64          `json:"chat_id,omitempty"` // 0 — конфигурация уровня плагина
+	Values   map[string]any `json:"values"`
+}
+
+// String возвращает строковое значение поля.
+func (pc *PluginConfig) String(slug string) (string, bool) {
+	v, ok := pc.Values[slug].(string)
+	return v, ok
+}
+
+// Float возвращает числовое значение поля.
+func (pc *PluginConfig) Float(slug string) (float64, bool) {
+	v, ok := pc.Values[slug].(float64)
+	return v, ok
+}
+
+// Int возвращает числовое значение поля как int64 (дробная часть отбрасывается).
+func (pc *PluginConfig) Int(slug string) (int64, bool) {
+	v, ok := pc.Values[slug].(float64)
+	return int64(v), ok
+}
+
+// Bool возвращает логическое значение поля.
+func (pc *PluginConfig) Bool(slug string) (bool, bool) {
+	v, ok := pc.Values[slug].(bool)
+	return v, ok
+}
+
+// FieldError — ошибка значения одного поля.
+type FieldError struct {
+	Field string
+	// Code — стабильный код ошибки: required, unknown_field, invalid_type,
+	// invalid_url, pattern_mismatch, out_of_range, invalid_option,
+	// invalid_json, schema_mismatch, invalid_condition (последние четыре и
+	// invalid_url — только для конфигурации).
+	Code    string
+	Message string
+}
+
+func (e FieldError) String() string { return e.Field + ": " + e.Message }
+
+// ConfigError — значения конфигурации не прошли проверку по config_fields.
+type ConfigError struct {
+	Fields []FieldError
+}
+
+func (e *ConfigError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.String()
+	}
+	return "integrat: invalid config: " + strings.Join(parts, "; ")
+}
+
+// ValidateConfig проверяет значения по определениям полей: обязательность,
+// тип, границы min/max, pattern, options для select/multiselect, schema
+// для json и условия visible_if/required_if. Значения secret в сообщениях
+// об ошибках не выводятся.
+// Возвращает *ConfigError или nil.
+// Нормализованные значения возвращает NormalizeConfig.
+func ValidateConfig(fields []ConfigField, values map[string]any) error {
+	_, err := NormalizeConfig(fields, values)
+	return err
+}
+
+// NormalizeConfig проверяет значения так же, как ValidateConfig, и
+// возвращает их в виде, который сохранит gateway: типы приведены
+// ("30" → 30 для number, "true" → true), подставлены default, скрытые
+// visible_if поля отброшены. values не изменяется. При ошибке —
+// *ConfigError.
+func NormalizeConfig(fields []ConfigField, values map[string]any) (map[string]any, error) {
+	defs := make([]validator.ConfigFieldDef, len(fields))
+	for i, f := range fields {
+		defs[i] = validator.ConfigFieldDef{
+			Slug:       f.Slug,
+			Label:      f.Label,
+			Type:       f.Type,
+			Required:   f.Required,
+			Default:    f.Default,
+			Min:        f.Min,
+			Max:        f.Max,
+			Pattern:    f.Pattern,
+			Group:      f.Group,
+			Order:      f.Order,
+			VisibleIf:  f.VisibleIf,
+			RequiredIf: f.RequiredIf,
+		}
+		if len(f.Schema) > 0 && string(f.Schema) != "null" {
+			var schema map[string]any
+			if err := json.Unmarshal(f.Schema, &schema); err != nil {
+				return nil, fmt.Errorf("integrat: config field %q: unmarshal schema: %w", f.Slug, err)
+			}
+			if err := defs[i].Schema.Encode(schema); err != nil {
+				return nil, fmt.Errorf("integrat: config field %q: encode schema: %w", f.Slug, err)
+			}
+		}
+		for _, o := range f.Options {
+			defs[i].Options = append(defs[i].Options, validator.ConfigOptionDef{Value: o.Value, Label: o.Label})
+		}
+	}
+
+	r := validator.ValidateConfigValues(defs, values)
+	if !r.OK() {
+		errs := make([]FieldError, len(r.Errors))
+		for i, e := range r.Errors {
+			errs[i] = FieldError{Field: e.Field, Code: e.Code, Message: e.MessageEN}
+		}
+		return nil, &ConfigError{Fields: errs}
+	}
+	return r.Values, nil
+}
+
+// ── API ─────────────────────────────────────────────────────────────────
+
+// GetPluginConfig возвращает конфигурацию уровня плагина.
+func (c *Client) GetPluginConfig(pluginID int64) (*PluginConfig, error) {
+	return c.getConfig(pluginID, 0)
+}
+
+// GetChatPluginConfig возвращает конфигурацию плагина в чате.
+func (c *Client) GetChatPluginConfig(pluginID, chatID int64) (*PluginConfig, error) {
+	return c.getConfig(pluginID, chatID)
+}
+
+// SetPluginConfig проверяет значения по config_fields плагина и сохраняет
+// конфигурацию уровня плагина. Отправляются нормализованные значения:
+// строки "7"/"true" приводятся к типу поля, незаданные поля получают default.
+// При ошибке проверки возвращает *ConfigError без запроса к API.
+func (c *Client) SetPluginConfig(pluginID int64, values map[string]any) (*PluginConfig, error) {
+	return c.setConfig(pluginID, 0, values)
+}
+
+// SetChatPluginConfig — то же, что SetPluginConfig, для конкретного чата.
+func (c *Client) SetChatPluginConfig(pluginID, chatID int64, values map[string]any) (*PluginConfig, error) {
+	return c.setConfig(pluginID, chatID, values)
+}
+
+func configPath(pluginID, chatID int64) string {
+	path := fmt.Sprintf("/v1/plugins/%d/config", pluginID)
+	if chatID != 0 {
+		path += fmt.Sprintf("?chat_id=%d", chatID)
+	}
+	return path
+}
+
+func (c *Client) getConfig(pluginID, chatID int64) (*PluginConfig, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", configPath(pluginID, chatID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var cfg PluginConfig
+	if err := json.Unmarshal(respBody, &cfg); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &cfg, nil
+}
+
+func (c *Client) setConfig(pluginID, chatID int64, values map[string]any) (*PluginConfig, error) {
+	plugin, err := c.GetPlugin(pluginID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := plugin.ParseConfigFields()
+	if err != nil {
+		return nil, err
+	}
+	values, err = NormalizeConfig(fields, values)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, _, err := c.doJSON(context.Background(), "PUT", configPath(pluginID, chatID), map[string]any{"values": values})
+	if err != nil {
+		return nil, err
+	}
+	var cfg PluginConfig
+	if err := json.Unmarshal(respBody, &cfg); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,205 @@
+package integrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testConfigFields = `[
+  {"slug":"channel","label":"Channel","type":"string","required":true},
+  {"slug":"backfill_days","label":"Days","type":"number","default":7},
+  {"slug":"notify","label":"Notify","type":"boolean"},
+  {"slug":"mode","label":"Mode","type":"select","options":[{"value":"fast","label":"Fast"},{"value":"slow","label":"Slow"}]}
+]`
+
+func configServer(t *testing.T, put *map[string]any, putPath *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/plugins/3":
+			io.WriteString(w, `{"id":3,"slug":"p","config_fields":`+testConfigFields+`}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/plugins/3/config":
+			io.WriteString(w, `{"plugin_id":3,"values":{"channel":"durov","backfill_days":14,"notify":true}}`)
+		case r.Method == "PUT" && r.URL.Path == "/v1/plugins/3/config":
+			*putPath = r.URL.RequestURI()
+			var body struct {
+				Values map[string]any `json:"values"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			*put = body.Values
+			json.NewEncoder(w).Encode(PluginConfig{PluginID: 3, Values: body.Values})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetPluginConfig_Typed(t *testing.T) {
+	var put map[string]any
+	var path string
+	c := NewWithURL("t", configServer(t, &put, &path).URL)
+
+	cfg, err := c.GetPluginConfig(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := cfg.String("channel"); s != "durov" {
+		t.Errorf("channel = %q", s)
+	}
+	if n, _ := cfg.Int("backfill_days"); n != 14 {
+		t.Errorf("backfill_days = %d", n)
+	}
+	if b, ok := cfg.Bool("notify"); !ok || !b {
+		t.Errorf("notify = %v, %v", b, ok)
+	}
+	if _, ok := cfg.Bool("channel"); ok {
+		t.Error("Bool(channel) ok for string value")
+	}
+}
+
+func TestSetChatPluginConfig(t *testing.T) {
+	var put map[string]any
+	var path string
+	c := NewWithURL("t", configServer(t, &put, &path).URL)
+
+	values := map[string]any{"channel": "durov", "backfill_days": 3, "mode": "fast"}
+	if _, err := c.SetChatPluginConfig(3, -100, values); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v1/plugins/3/config?chat_id=-100" {
+		t.Errorf("PUT path = %q", path)
+	}
+	if put["mode"] != "fast" {
+		t.Errorf("sent values = %v", put)
+	}
+}
+
+func TestSetPluginConfig_Invalid(t *testing.T) {
+	var put map[string]any
+	var path string
+	c := NewWithURL("t", configServer(t, &put, &path).URL)
+
+	_, err := c.SetPluginConfig(3, map[string]any{
+		"backfill_days": "seven",
+		"notify":        "yes",
+		"mode":          "turbo",
+		"extra":         1,
+	})
+	var ce *ConfigError
+	if !errors.As(err, &ce) {
+		t.Fatalf("err = %v, want *ConfigError", err)
+	}
+	got := map[string]string{}
+	for _, f := range ce.Fields {
+		got[f.Field] = f.Code
+	}
+	for field, code := range map[string]string{
+		"channel":       "required",
+		"backfill_days": "invalid_type",
+		"notify":        "invalid_type",
+		"mode":          "invalid_option",
+		"extra":         "unknown_field",
+	} {
+		if got[field] != code {
+			t.Errorf("%s: code = %q, want %q (%v)", field, got[field], code, ce.Fields)
+		}
+	}
+	want := `integrat: invalid config: channel: required; backfill_days: must be a number, got string; ` +
+		`notify: must be a boolean, got string; mode: "turbo" is not one of the options (fast, slow); extra: unknown field`
+	if err.Error() != want {
+		t.Errorf("error = %q\nwant    %q", err.Error(), want)
+	}
+	if put != nil {
+		t.Error("invalid config was sent to API")
+	}
+}
+
+func TestParseConfigFields_Empty(t *testing.T) {
+	p := &Plugin{ConfigFields: json.RawMessage("null")}
+	fields, err := p.ParseConfigFields()
+	if err != nil || fields != nil {
+		t.Errorf("fields = %v, err = %v", fields, err)
+	}
+}
+
+func TestSetPluginConfig_Normalized(t *testing.T) {
+	var put map[string]any
+	var path string
+	c := NewWithURL("t", configServer(t, &put, &path).URL)
+
+	if _, err := c.SetPluginConfig(3, map[string]any{"channel": "durov", "notify": "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if put["notify"] != true {
+		t.Errorf("notify = %#v, want true", put["notify"])
+	}
+	if put["backfill_days"] != float64(7) {
+		t.Errorf("backfill_days = %#v, want default 7", put["backfill_days"])
+	}
+}
+
+func TestValidateConfig_RichTypes(t *testing.T) {
+	var fields []ConfigField
+	err := json.Unmarshal([]byte(`[
+	  {"slug":"api_key","label":"Key","type":"secret","required":true},
+	  {"slug":"limit","label":"Limit","type":"integer","min":1,"max":10},
+	  {"slug":"channels","label":"Channels","type":"json",
+	   "schema":{"type":"array","items":{"type":"string"}}}
+	]`), &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateConfig(fields, map[string]any{
+		"api_key":  "s3cr3t",
+		"limit":    5,
+		"channels": `["durov"]`,
+	}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = ValidateConfig(fields, map[string]any{"api_key": "s3cr3t", "limit": 11, "channels": `[1]`})
+	var ce *ConfigError
+	if !errors.As(err, &ce) || len(ce.Fields) != 2 {
+		t.Fatalf("err = %v, want 2 field errors", err)
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("secret echoed: %v", err)
+	}
+}
+
+func TestNormalizeConfig(t *testing.T) {
+	var fields []ConfigField
+	err := json.Unmarshal([]byte(`[
+	  {"slug":"limit","label":"Limit","type":"integer","default":5},
+	  {"slug":"ratio","label":"Ratio","type":"number"},
+	  {"slug":"notify","label":"Notify","type":"boolean"}
+	]`), &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := map[string]any{"ratio": "0.5", "notify": "true"}
+	got, err := NormalizeConfig(fields, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["limit"] != int64(5) || got["ratio"] != 0.5 || got["notify"] != true {
+		t.Errorf("normalized = %#v", got)
+	}
+	if _, ok := in["limit"]; ok || in["ratio"] != "0.5" {
+		t.Errorf("input modified: %#v", in)
+	}
+
+	if _, err := NormalizeConfig(fields, map[string]any{"limit": "many"}); !errors.As(err, new(*ConfigError)) {
+		t.Errorf("err = %v, want *ConfigError", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 )
 
 // Sentinel-ошибки для проверки через errors.Is.
@@ -14,6 +16,8 @@ var (
 	ErrForbidden    = errors.New("integrat: access denied")
 	ErrNotFound     = errors.New("integrat: not found")
 	ErrConflict     = errors.New("integrat: conflict")
+	ErrPayment      = errors.New("integrat: payment required")
+	ErrRateLimited  = errors.New("integrat: rate limit exceeded")
 	ErrProvider     = errors.New("integrat: provider unavailable")
 )
 
@@ -23,6 +27,10 @@ type APIError struct {
 	Code       string // Код ошибки из API (например "not_found", "limit_exceeded")
 	Message    string // Текст ошибки
 	Err        error  // Базовая sentinel-ошибка для errors.Is
+
+	// RetryAfter — через сколько можно повторить запрос (Retry-After или
+	// retry_after в теле ответа); для 429, иначе 0.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -38,14 +46,15 @@ func (e *APIError) Unwrap() error {
 }
 
 // newAPIError создаёт APIError из HTTP-ответа.
-func newAPIError(status int, body []byte) *APIError {
+func newAPIError(status int, header http.Header, body []byte) *APIError {
 	ae := &APIError{StatusCode: status}
 
 	// Пытаемся распарсить JSON-ответ API
 	var errResp struct {
-		Error   string `json:"error"`
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Error      string `json:"error"`
+		Code       string `json:"code"`
+		Message    string `json:"message"`
+		RetryAfter int    `json:"retry_after"` // секунды, для 429
 	}
 	if json.Unmarshal(body, &errResp) == nil {
 		if errResp.Error != "" {
@@ -67,8 +76,16 @@ func newAPIError(status int, body []byte) *APIError {
 		ae.Err = ErrForbidden
 	case status == 404:
 		ae.Err = ErrNotFound
+	case status == http.StatusPaymentRequired:
+		ae.Err = ErrPayment
 	case status == 409:
 		ae.Err = ErrConflict
+	case status == http.StatusTooManyRequests:
+		ae.Err = ErrRateLimited
+		ae.RetryAfter = retryAfter(header)
+		if ae.RetryAfter == 0 && errResp.RetryAfter > 0 {
+			ae.RetryAfter = time.Duration(errResp.RetryAfter) * time.Second
+		}
 	case status >= 502 && status <= 504:
 		ae.Err = ErrProvider
 	}
@@ -0,0 +1,59 @@
+package integrat
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError_Sentinels(t *testing.T) {
+	tests := map[int]error{
+		401: ErrUnauthorized,
+		402: ErrPayment,
+		403: ErrForbidden,
+		404: ErrNotFound,
+		409: ErrConflict,
+		429: ErrRateLimited,
+		503: ErrProvider,
+	}
+	for status, want := range tests {
+		if err := newAPIError(status, nil, []byte(`{"error":"x"}`)); !errors.Is(err, want) {
+			t.Errorf("%d: err = %v, want %v", status, err, want)
+		}
+	}
+}
+
+func TestNewAPIError_RetryAfter(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "7")
+	if ae := newAPIError(429, h, []byte(`{"code":"rate_limited","retry_after":30}`)); ae.RetryAfter != 7*time.Second {
+		t.Errorf("header: RetryAfter = %v", ae.RetryAfter)
+	}
+	if ae := newAPIError(429, http.Header{}, []byte(`{"code":"rate_limited","retry_after":30}`)); ae.RetryAfter != 30*time.Second {
+		t.Errorf("body: RetryAfter = %v", ae.RetryAfter)
+	}
+	if ae := newAPIError(503, h, nil); ae.RetryAfter != 0 {
+		t.Errorf("503: RetryAfter = %v, want 0", ae.RetryAfter)
+	}
+}
+
+func TestQuery_RateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"code":"rate_limited","message":"10 requests per 1m per chat"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewWithURL("t", srv.URL).Query("p", "e", nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.Code != "rate_limited" || ae.RetryAfter != 12*time.Second {
+		t.Errorf("APIError = %+v", ae)
+	}
+}
@@ -0,0 +1,79 @@
+// Подключение плагинов к чатам: установка с начальной конфигурацией,
+// удаление и списки подключений со стороны чата и со стороны плагина.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Installation — плагин, подключённый к чату.
+type Installation struct {
+	ID          int64          `json:"id"`
+	ChatID      int64          `json:"chat_id"`
+	PluginID    int64          `json:"plugin_id"`
+	Plugin      string         `json:"plugin"`           // slug плагина
+	Config      map[string]any `json:"config,omitempty"` // значения secret не возвращаются
+	InstalledBy int64          `json:"installed_by"`
+	InstalledAt string         `json:"installed_at"`
+}
+
+// InstallPlugin подключает плагин к чату. config проверяется по
+// config_fields плагина так же, как в SetChatPluginConfig: при ошибке
+// возвращается *ConfigError без запроса установки. Повторная установка —
+// ErrConflict.
+func (c *Client) InstallPlugin(chatID int64, slug string, config map[string]any) (*Installation, error) {
+	detail, err := c.GetPluginBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := detail.Plugin.ParseConfigFields()
+	if err != nil {
+		return nil, err
+	}
+	values, err := NormalizeConfig(fields, config)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{"plugin": slug, "config": values}
+	respBody, _, err := c.doJSON(context.Background(), "POST", fmt.Sprintf("/v1/chats/%d/plugins", chatID), body)
+	if err != nil {
+		return nil, err
+	}
+	var inst Installation
+	if err := json.Unmarshal(respBody, &inst); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &inst, nil
+}
+
+// UninstallPlugin отключает плагин от чата; конфигурация чата удаляется.
+func (c *Client) UninstallPlugin(chatID int64, slug string) error {
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/chats/%d/plugins/%s", chatID, url.PathEscape(slug)), nil)
+	return err
+}
+
+// ListChatPlugins возвращает плагины, подключённые к чату.
+func (c *Client) ListChatPlugins(chatID int64) ([]Installation, error) {
+	return c.listInstallations(fmt.Sprintf("/v1/chats/%d/plugins", chatID))
+}
+
+// ListPluginChats возвращает чаты, к которым подключён плагин (для владельца).
+func (c *Client) ListPluginChats(pluginID int64) ([]Installation, error) {
+	return c.listInstallations(fmt.Sprintf("/v1/plugins/%d/chats", pluginID))
+}
+
+func (c *Client) listInstallations(path string) ([]Installation, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list []Installation
+	if err := json.Unmarshal(respBody, &list); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,80 @@
+package integrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func installServer(t *testing.T, posted *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/marketplace/channel-mcp":
+			io.WriteString(w, `{"plugin":{"id":3,"slug":"channel-mcp","config_fields":`+testConfigFields+`},"endpoints":[]}`)
+		case "POST /v1/chats/-100/plugins":
+			json.NewDecoder(r.Body).Decode(posted)
+			io.WriteString(w, `{"id":11,"chat_id":-100,"plugin_id":3,"plugin":"channel-mcp","config":{"channel":"durov"}}`)
+		case "DELETE /v1/chats/-100/plugins/channel-mcp":
+		case "GET /v1/chats/-100/plugins":
+			io.WriteString(w, `[{"id":11,"chat_id":-100,"plugin":"channel-mcp"}]`)
+		case "GET /v1/plugins/3/chats":
+			io.WriteString(w, `[{"id":11,"chat_id":-100,"plugin":"channel-mcp"},{"id":12,"chat_id":-200,"plugin":"channel-mcp"}]`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInstallPlugin(t *testing.T) {
+	var posted map[string]any
+	c := NewWithURL("t", installServer(t, &posted).URL)
+
+	inst, err := c.InstallPlugin(-100, "channel-mcp", map[string]any{"channel": "durov", "notify": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.ID != 11 || inst.PluginID != 3 {
+		t.Errorf("installation = %+v", inst)
+	}
+	cfg, _ := posted["config"].(map[string]any)
+	if posted["plugin"] != "channel-mcp" || cfg["notify"] != true || cfg["backfill_days"] != float64(7) {
+		t.Errorf("posted = %v", posted)
+	}
+}
+
+func TestInstallPlugin_InvalidConfig(t *testing.T) {
+	var posted map[string]any
+	c := NewWithURL("t", installServer(t, &posted).URL)
+
+	_, err := c.InstallPlugin(-100, "channel-mcp", map[string]any{"mode": "turbo"})
+	var ce *ConfigError
+	if !errors.As(err, &ce) {
+		t.Fatalf("err = %v, want *ConfigError", err)
+	}
+	if posted != nil {
+		t.Error("installation requested with invalid config")
+	}
+}
+
+func TestInstallations(t *testing.T) {
+	c := NewWithURL("t", installServer(t, new(map[string]any)).URL)
+
+	if err := c.UninstallPlugin(-100, "channel-mcp"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.ListChatPlugins(-100)
+	if err != nil || len(list) != 1 || list[0].Plugin != "channel-mcp" {
+		t.Errorf("chat plugins = %+v, %v", list, err)
+	}
+	list, err = c.ListPluginChats(3)
+	if err != nil || len(list) != 2 || list[1].ChatID != -200 {
+		t.Errorf("plugin chats = %+v, %v", list, err)
+	}
+}
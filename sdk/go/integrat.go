@@ -4,29 +4,61 @@ package integrat
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const DefaultBaseURL = "https://integrat.plag.space"
 
+// DefaultUserAgent — User-Agent по умолчанию.
+const DefaultUserAgent = "integrat-go-sdk"
+
 // Client — клиент для работы с Integrat API.
 type Client struct {
-	BaseURL    string
-	Token      string
-	HTTPClient *http.Client
+	BaseURL       string
+	Token         string
+	TokenSource   TokenSource // источник токена; если задан, Token игнорируется
+	HTTPClient    *http.Client
+	UserAgent     string
+	Retry         RetryPolicy  // повтор временных ошибок; нулевое значение — без повторов
+	Cache         Cache        // клиентский кеш ответов Query; nil — выключен
+	CacheScope    string       // изолирует записи общего Cache; по умолчанию — хеш токена
+	DefaultChatID int64        // chat_id для Query и запросов с chatID == 0
+	Observer      Observer     // события начала/конца вызовов (трейсинг, метрики); nil — выключено
+	Logger        *slog.Logger // диагностика (Debug — запросы/ответы, Warn — stale); nil — выключено
+
+	// ValidateParams включает локальную проверку params по params_schema
+	// эндпоинта до отправки запроса (с подстановкой default);
+	// несоответствие — *ParamsError (errors.Is ErrInvalidParams).
+	ValidateParams bool
+	// StrictResponses включает проверку QueryResponse.Data по response_schema
+	// эндпоинта; несоответствие — *ResponseError (errors.Is ErrInvalidResponse).
+	StrictResponses bool
+	// SchemaTTL — срок кеша описаний эндпоинтов для ValidateParams и
+	// StrictResponses; 0 — DefaultSchemaTTL. Сбросить кеш сразу —
+	// InvalidateSchemas.
+	SchemaTTL time.Duration
+
+	mwMu       sync.Mutex
+	middleware []Middleware // copy-on-write под mwMu
+	endpoints  endpointCache
 }
 
 // New создаёт клиент с API-токеном.
 func New(token string) *Client {
 	return &Client{
-		BaseURL: DefaultBaseURL,
-		Token:   token,
+		BaseURL:   DefaultBaseURL,
+		Token:     token,
+		UserAgent: DefaultUserAgent,
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -48,6 +80,7 @@ type QueryRequest struct {
 	Endpoint string         `json:"endpoint"`
 	ChatID   int64          `json:"chat_id,omitempty"`
 	Params   map[string]any `json:"params,omitempty"`
+	Stream   bool           `json:"stream,omitempty"`
 }
 
 // QueryResponse — ответ с данными.
@@ -88,11 +121,23 @@ type Plugin struct {
 	Description  string          `json:"description"`
 	Version      string          `json:"version"`
 	BaseURL      string          `json:"base_url"`
+	ProxyMode    string          `json:"proxy_mode,omitempty"`
 	OwnerID      int64           `json:"owner_id"`
 	Status       string          `json:"status"`
 	ConfigFields json.RawMessage `json:"config_fields"`
+	Tags         []string        `json:"tags,omitempty"`
+	Rating       float64         `json:"rating,omitempty"`       // средняя оценка 1–5
+	RatingCount  int             `json:"rating_count,omitempty"` // число оценок
+	Health       string          `json:"health,omitempty"`       // HealthUp, HealthDegraded, HealthDown
 }
 
+// Состояние провайдера по health-check gateway.
+const (
+	HealthUp       = "up"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
 // Endpoint — информация об эндпоинте плагина.
 type Endpoint struct {
 	ID             int64           `json:"id"`
@@ -107,14 +152,28 @@ type Endpoint struct {
 	CacheTTL       int             `json:"cache_ttl"`
 	ProxyPath      string          `json:"proxy_path,omitempty"`
 	ProxyMethod    string          `json:"proxy_method,omitempty"`
+	Streaming      bool            `json:"streaming,omitempty"`
+	Subscribe      bool            `json:"subscribe,omitempty"` // обновления через SubscribeUpdates
+	RateLimit      *RateLimit      `json:"rate_limit,omitempty"`
 	CreatedAt      string          `json:"created_at"`
 }
 
+// RateLimit — ограничение запросов к эндпоинту (rate_limit в integrat.yaml).
+// При превышении gateway отвечает 429 — ошибка ErrRateLimited.
+type RateLimit struct {
+	Requests    int    `json:"requests,omitempty"`    // запросов за Window
+	Window      string `json:"window,omitempty"`      // длительность окна: "1m", "1h"
+	Per         string `json:"per,omitempty"`         // chat, user, token, global
+	Burst       int    `json:"burst,omitempty"`       // запас сверх Requests
+	Concurrency int    `json:"concurrency,omitempty"` // одновременных запросов на ключ
+}
+
 // CreatePluginParams — параметры создания плагина.
 type CreatePluginParams struct {
 	Name         string          `json:"name"`
 	Slug         string          `json:"slug"`
-	BaseURL      string          `json:"base_url"`
+	BaseURL      string          `json:"base_url,omitempty"`
+	ProxyMode    string          `json:"proxy_mode,omitempty"` // ProxyModeProxy (по умолчанию), ProxyModeRedirect, ProxyModePush
 	Description  string          `json:"description,omitempty"`
 	GithubURL    string          `json:"github_url,omitempty"`
 	Version      string          `json:"version,omitempty"`
@@ -127,104 +186,253 @@ type UpdatePluginParams struct {
 	Description *string `json:"description,omitempty"`
 	GithubURL   *string `json:"github_url,omitempty"`
 	BaseURL     *string `json:"base_url,omitempty"`
+	ProxyMode   *string `json:"proxy_mode,omitempty"`
 	Version     *string `json:"version,omitempty"`
 }
 
 // CreateEndpointParams — параметры создания эндпоинта.
 type CreateEndpointParams struct {
-	Name         string          `json:"name"`
-	Slug         string          `json:"slug"`
-	Description  string          `json:"description,omitempty"`
-	AccessTier   string          `json:"access_tier,omitempty"`
-	DataType     string          `json:"data_type,omitempty"`
-	CacheTTL     int             `json:"cache_ttl,omitempty"`
-	ProxyPath    string          `json:"proxy_path,omitempty"`
-	ProxyMethod  string          `json:"proxy_method,omitempty"`
-	ParamsSchema json.RawMessage `json:"params_schema,omitempty"`
+	Name           string          `json:"name"`
+	Slug           string          `json:"slug"`
+	Description    string          `json:"description,omitempty"`
+	AccessTier     string          `json:"access_tier,omitempty"`
+	DataType       string          `json:"data_type,omitempty"`
+	CacheTTL       int             `json:"cache_ttl,omitempty"`
+	ProxyPath      string          `json:"proxy_path,omitempty"`
+	ProxyMethod    string          `json:"proxy_method,omitempty"`
+	Streaming      bool            `json:"streaming,omitempty"`
+	Subscribe      bool            `json:"subscribe,omitempty"`
+	ParamsSchema   json.RawMessage `json:"params_schema,omitempty"`
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"`
+	RateLimit      *RateLimit      `json:"rate_limit,omitempty"`
 }
 
 // UpdateEndpointParams — параметры обновления эндпоинта (nil = не менять).
 type UpdateEndpointParams struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
-	AccessTier  *string `json:"access_tier,omitempty"`
-	DataType    *string `json:"data_type,omitempty"`
-	CacheTTL    *int    `json:"cache_ttl,omitempty"`
-	ProxyPath   *string `json:"proxy_path,omitempty"`
-	ProxyMethod *string `json:"proxy_method,omitempty"`
-}
-
-// MarketplaceSearchParams — параметры поиска в маркетплейсе.
+	Name           *string         `json:"name,omitempty"`
+	Description    *string         `json:"description,omitempty"`
+	AccessTier     *string         `json:"access_tier,omitempty"`
+	DataType       *string         `json:"data_type,omitempty"`
+	CacheTTL       *int            `json:"cache_ttl,omitempty"`
+	ProxyPath      *string         `json:"proxy_path,omitempty"`
+	ProxyMethod    *string         `json:"proxy_method,omitempty"`
+	Streaming      *bool           `json:"streaming,omitempty"`
+	Subscribe      *bool           `json:"subscribe,omitempty"`
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"` // nil — без изменений
+	RateLimit      *RateLimit      `json:"rate_limit,omitempty"`
+}
+
+// MarketplaceSearchParams — параметры поиска в маркетплейсе. Пустые
+// поля не фильтруют.
 type MarketplaceSearchParams struct {
-	Query    string `json:"q,omitempty"`
-	Category string `json:"category,omitempty"`
-	Sort     string `json:"sort,omitempty"`
-	Page     int    `json:"page,omitempty"`
-	Limit    int    `json:"limit,omitempty"`
+	Query      string   `json:"q,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Sort       string   `json:"sort,omitempty"`
+	Page       int      `json:"page,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+	Tags       []string `json:"tags,omitempty"`        // все перечисленные теги
+	AccessTier string   `json:"access_tier,omitempty"` // есть эндпоинт с таким access: open, gated, private
+	DataType   string   `json:"data_type,omitempty"`   // есть эндпоинт с таким data_type: basic, medium, complex
+	PriceMin   *int64   `json:"price_min,omitempty"`   // минимальная цена вызова, Stars
+	PriceMax   *int64   `json:"price_max,omitempty"`   // 0 — только бесплатные
+	OwnerID    int64    `json:"owner_id,omitempty"`
+	MinRating  float64  `json:"min_rating,omitempty"` // 1–5
+	Health     string   `json:"health,omitempty"`     // HealthUp, HealthDegraded, HealthDown
 }
 
 // MarketplaceResult — результат поиска в маркетплейсе.
 type MarketplaceResult struct {
-	Plugins []Plugin `json:"plugins"`
-	Total   int      `json:"total"`
-	Page    int      `json:"page"`
-	Pages   int      `json:"pages"`
+	Plugins []Plugin           `json:"plugins"`
+	Total   int                `json:"total"`
+	Page    int                `json:"page"`
+	Pages   int                `json:"pages"`
+	Facets  *MarketplaceFacets `json:"facets,omitempty"`
+}
+
+// MarketplaceFacets — число плагинов по значениям фильтров. Считается по
+// выдаче с учётом всех фильтров, кроме фильтра самого фасета, — так
+// значения остаются доступными для выбора.
+type MarketplaceFacets struct {
+	Categories  map[string]int `json:"categories,omitempty"`
+	Tags        map[string]int `json:"tags,omitempty"`
+	AccessTiers map[string]int `json:"access_tiers,omitempty"`
+	DataTypes   map[string]int `json:"data_types,omitempty"`
+	Health      map[string]int `json:"health,omitempty"`
+	Prices      []PriceBucket  `json:"prices,omitempty"`
+}
+
+// PriceBucket — диапазон цены вызова в Stars. Max == nil — без верхней
+// границы.
+type PriceBucket struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max,omitempty"`
+	Count int    `json:"count"`
 }
 
 // PluginDetail — детальная информация о плагине из маркетплейса.
 type PluginDetail struct {
 	Plugin    Plugin     `json:"plugin"`
 	Endpoints []Endpoint `json:"endpoints"`
+	Plans     []Plan     `json:"plans,omitempty"`
 }
 
 // ── Внутренний HTTP ─────────────────────────────────────────────────────
 
-// doRequest выполняет HTTP-запрос и возвращает тело ответа.
-// При статусе >= 400 возвращает *APIError.
-func (c *Client) doRequest(method, path string, body io.Reader) ([]byte, int, error) {
-	req, err := http.NewRequest(method, c.BaseURL+path, body)
+// newRequest создаёт запрос к API с заголовками авторизации.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("integrat: create request: %w", err)
+		return nil, fmt.Errorf("integrat: create request: %w", err)
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return req, nil
+}
 
-	resp, err := c.HTTPClient.Do(req)
+// token возвращает актуальный токен из TokenSource или поля Token.
+func (c *Client) token(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.Token, nil
+	}
+	token, err := c.TokenSource.Token(ctx)
 	if err != nil {
-		return nil, 0, fmt.Errorf("integrat: http request: %w", err)
+		return "", fmt.Errorf("integrat: token: %w", err)
+	}
+	return token, nil
+}
+
+// send отправляет запрос с событиями Observer и читает тело ответа целиком.
+// При статусе >= 400 возвращает *APIError (ответ при этом не nil).
+func (c *Client) send(req *http.Request, call *CallInfo) (*http.Response, []byte, error) {
+	req, finish := c.observe(req, call)
+	res := &CallResult{}
+	start := time.Now()
+	defer func() {
+		finish(res)
+		c.logResult(req.Context(), call, res, time.Since(start))
+	}()
+
+	c.log(req.Context(), slog.LevelDebug, "integrat request", callAttrs(call)...)
+
+	resp, err := c.doAuth(req, call)
+	if err != nil {
+		res.Err = fmt.Errorf("integrat: http request: %w", err)
+		return nil, nil, res.Err
 	}
 	defer resp.Body.Close()
 
+	res.StatusCode = resp.StatusCode
+	res.Cached = resp.Header.Get("X-Integrat-Cached") == "true"
+	res.Stale = resp.Header.Get("X-Integrat-Stale") == "true"
+
 	respBody, err := io.ReadAll(resp.Body)
+	res.Bytes = int64(len(respBody))
 	if err != nil {
-		return nil, 0, fmt.Errorf("integrat: read response: %w", err)
+		res.Err = fmt.Errorf("integrat: read response: %w", err)
+		return nil, nil, res.Err
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, resp.StatusCode, newAPIError(resp.StatusCode, respBody)
+		res.Err = newAPIError(resp.StatusCode, resp.Header, respBody)
+		return resp, nil, res.Err
+	}
+
+	return resp, respBody, nil
+}
+
+// logResult пишет итог вызова: сводку ответа (Debug), ошибку (Debug —
+// она и так возвращается вызывающему) и отдачу устаревших данных (Warn).
+func (c *Client) logResult(ctx context.Context, call *CallInfo, res *CallResult, d time.Duration) {
+	attrs := append(callAttrs(call),
+		slog.Int("status", res.StatusCode),
+		slog.Int64("bytes", res.Bytes),
+		slog.Duration("duration", d),
+	)
+	if res.Err != nil {
+		c.log(ctx, slog.LevelDebug, "integrat request failed", append(attrs, slog.String("error", res.Err.Error()))...)
+		return
 	}
+	attrs = append(attrs, slog.Bool("cached", res.Cached), slog.Bool("stale", res.Stale))
+	if res.Stale {
+		c.log(ctx, slog.LevelWarn, "integrat stale data served from cache (provider offline)", attrs...)
+		return
+	}
+	c.log(ctx, slog.LevelDebug, "integrat response", attrs...)
+}
 
+// doRequest выполняет HTTP-запрос и возвращает тело ответа.
+// При статусе >= 400 возвращает *APIError.
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) ([]byte, int, error) {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, respBody, err := c.send(req, &CallInfo{Method: method, Path: path})
+	if err != nil {
+		if resp != nil {
+			return nil, resp.StatusCode, err
+		}
+		return nil, 0, err
+	}
 	return respBody, resp.StatusCode, nil
 }
 
 // doJSON маршалит body в JSON и вызывает doRequest.
-func (c *Client) doJSON(method, path string, body any) ([]byte, int, error) {
+func (c *Client) doJSON(ctx context.Context, method, path string, body any) ([]byte, int, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, 0, fmt.Errorf("integrat: marshal: %w", err)
 	}
-	return c.doRequest(method, path, bytes.NewReader(data))
+	return c.doRequest(ctx, method, path, bytes.NewReader(data))
 }
 
 // ── Query ───────────────────────────────────────────────────────────────
 
-// Query выполняет запрос данных через прокси (dev-режим, без привязки к чату).
+// Query выполняет запрос данных через прокси (dev-режим, без привязки к чату;
+// если задан DefaultChatID — в его контексте).
 func (c *Client) Query(plugin, endpoint string, params map[string]any) (*QueryResponse, error) {
-	return c.QueryInChat(plugin, endpoint, 0, params)
+	return c.QueryInChatContext(context.Background(), plugin, endpoint, 0, params)
+}
+
+// QueryContext — Query с контекстом: отмена, дедлайн и родительский span
+// трассировки (см. пакет telemetry) берутся из ctx.
+func (c *Client) QueryContext(ctx context.Context, plugin, endpoint string, params map[string]any) (*QueryResponse, error) {
+	return c.QueryInChatContext(ctx, plugin, endpoint, 0, params)
 }
 
 // QueryInChat выполняет запрос данных в контексте конкретного чата.
+// При chatID == 0 используется DefaultChatID.
 func (c *Client) QueryInChat(plugin, endpoint string, chatID int64, params map[string]any) (*QueryResponse, error) {
+	return c.QueryInChatContext(context.Background(), plugin, endpoint, chatID, params)
+}
+
+// QueryInChatContext — QueryInChat с контекстом.
+func (c *Client) QueryInChatContext(ctx context.Context, plugin, endpoint string, chatID int64, params map[string]any) (*QueryResponse, error) {
+	if chatID == 0 {
+		chatID = c.DefaultChatID
+	}
+	call := &CallInfo{
+		Method:   "POST",
+		Path:     "/v1/query",
+		Plugin:   plugin,
+		Endpoint: endpoint,
+	}
+
+	if c.ValidateParams {
+		var err error
+		if params, err = c.checkParams(ctx, call, params); err != nil {
+			return nil, err
+		}
+	}
+
 	qr := QueryRequest{
 		Plugin:   plugin,
 		Endpoint: endpoint,
@@ -237,26 +445,24 @@ func (c *Client) QueryInChat(plugin, endpoint string, chatID int64, params map[s
 		return nil, fmt.Errorf("integrat: marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", c.BaseURL+"/v1/query", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("integrat: create request: %w", err)
+	var key string
+	if c.Cache != nil {
+		if key, err = c.cacheKey(ctx, body); err != nil {
+			return nil, err
+		}
+		if cached, ok := c.cacheGet(ctx, call, key); ok {
+			return cached, nil
+		}
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.Token)
 
-	httpResp, err := c.HTTPClient.Do(httpReq)
+	httpReq, err := c.newRequest(ctx, "POST", "/v1/query", bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("integrat: http request: %w", err)
+		return nil, err
 	}
-	defer httpResp.Body.Close()
 
-	respBody, err := io.ReadAll(httpResp.Body)
+	httpResp, respBody, err := c.send(httpReq, call)
 	if err != nil {
-		return nil, fmt.Errorf("integrat: read response: %w", err)
-	}
-
-	if httpResp.StatusCode >= 400 {
-		return nil, newAPIError(httpResp.StatusCode, respBody)
+		return nil, err
 	}
 
 	var result QueryResponse
@@ -268,6 +474,14 @@ func (c *Client) QueryInChat(plugin, endpoint string, chatID int64, params map[s
 	result.Cached = httpResp.Header.Get("X-Integrat-Cached") == "true"
 	result.Stale = httpResp.Header.Get("X-Integrat-Stale") == "true"
 
+	if c.StrictResponses {
+		if err := c.checkResponse(ctx, call, result.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	c.cacheSet(ctx, call, key, &result, httpResp.Header.Get("X-Integrat-TTL"))
+
 	return &result, nil
 }
 
@@ -275,7 +489,7 @@ func (c *Client) QueryInChat(plugin, endpoint string, chatID int64, params map[s
 
 // ListPlugins возвращает плагины текущего пользователя.
 func (c *Client) ListPlugins() ([]Plugin, error) {
-	respBody, _, err := c.doRequest("GET", "/v1/plugins", nil)
+	respBody, _, err := c.doRequest(context.Background(), "GET", "/v1/plugins", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -288,7 +502,7 @@ func (c *Client) ListPlugins() ([]Plugin, error) {
 
 // CreatePlugin создаёт новый плагин.
 func (c *Client) CreatePlugin(params CreatePluginParams) (*Plugin, error) {
-	respBody, _, err := c.doJSON("POST", "/v1/plugins", params)
+	respBody, _, err := c.doJSON(context.Background(), "POST", "/v1/plugins", params)
 	if err != nil {
 		return nil, err
 	}
@@ -301,7 +515,7 @@ func (c *Client) CreatePlugin(params CreatePluginParams) (*Plugin, error) {
 
 // GetPlugin возвращает плагин по ID.
 func (c *Client) GetPlugin(id int64) (*Plugin, error) {
-	respBody, _, err := c.doRequest("GET", fmt.Sprintf("/v1/plugins/%d", id), nil)
+	respBody, _, err := c.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/plugins/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -314,7 +528,7 @@ func (c *Client) GetPlugin(id int64) (*Plugin, error) {
 
 // UpdatePlugin обновляет плагин.
 func (c *Client) UpdatePlugin(id int64, params UpdatePluginParams) (*Plugin, error) {
-	respBody, _, err := c.doJSON("PUT", fmt.Sprintf("/v1/plugins/%d", id), params)
+	respBody, _, err := c.doJSON(context.Background(), "PUT", fmt.Sprintf("/v1/plugins/%d", id), params)
 	if err != nil {
 		return nil, err
 	}
@@ -327,7 +541,7 @@ func (c *Client) UpdatePlugin(id int64, params UpdatePluginParams) (*Plugin, err
 
 // DeletePlugin удаляет плагин.
 func (c *Client) DeletePlugin(id int64) error {
-	_, _, err := c.doRequest("DELETE", fmt.Sprintf("/v1/plugins/%d", id), nil)
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/plugins/%d", id), nil)
 	return err
 }
 
@@ -335,7 +549,7 @@ func (c *Client) DeletePlugin(id int64) error {
 
 // ListEndpoints возвращает эндпоинты плагина.
 func (c *Client) ListEndpoints(pluginID int64) ([]Endpoint, error) {
-	respBody, _, err := c.doRequest("GET", fmt.Sprintf("/v1/plugins/%d/endpoints", pluginID), nil)
+	respBody, _, err := c.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/plugins/%d/endpoints", pluginID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -348,7 +562,7 @@ func (c *Client) ListEndpoints(pluginID int64) ([]Endpoint, error) {
 
 // CreateEndpoint создаёт эндпоинт для плагина.
 func (c *Client) CreateEndpoint(pluginID int64, params CreateEndpointParams) (*Endpoint, error) {
-	respBody, _, err := c.doJSON("POST", fmt.Sprintf("/v1/plugins/%d/endpoints", pluginID), params)
+	respBody, _, err := c.doJSON(context.Background(), "POST", fmt.Sprintf("/v1/plugins/%d/endpoints", pluginID), params)
 	if err != nil {
 		return nil, err
 	}
@@ -361,7 +575,7 @@ func (c *Client) CreateEndpoint(pluginID int64, params CreateEndpointParams) (*E
 
 // UpdateEndpoint обновляет эндпоинт.
 func (c *Client) UpdateEndpoint(pluginID, endpointID int64, params UpdateEndpointParams) (*Endpoint, error) {
-	respBody, _, err := c.doJSON("PUT", fmt.Sprintf("/v1/plugins/%d/endpoints/%d", pluginID, endpointID), params)
+	respBody, _, err := c.doJSON(context.Background(), "PUT", fmt.Sprintf("/v1/plugins/%d/endpoints/%d", pluginID, endpointID), params)
 	if err != nil {
 		return nil, err
 	}
@@ -374,14 +588,20 @@ func (c *Client) UpdateEndpoint(pluginID, endpointID int64, params UpdateEndpoin
 
 // DeleteEndpoint удаляет эндпоинт.
 func (c *Client) DeleteEndpoint(pluginID, endpointID int64) error {
-	_, _, err := c.doRequest("DELETE", fmt.Sprintf("/v1/plugins/%d/endpoints/%d", pluginID, endpointID), nil)
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/plugins/%d/endpoints/%d", pluginID, endpointID), nil)
 	return err
 }
 
 // ── Marketplace ─────────────────────────────────────────────────────────
 
-// SearchMarketplace ищет плагины в маркетплейсе.
+// SearchMarketplace ищет плагины в маркетплейсе. Некорректные фильтры
+// (рейтинг вне 1–5, PriceMin > PriceMax, неизвестные значения) отклоняются
+// без запроса.
 func (c *Client) SearchMarketplace(params MarketplaceSearchParams) (*MarketplaceResult, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
 	v := url.Values{}
 	if params.Query != "" {
 		v.Set("q", params.Query)
@@ -398,13 +618,37 @@ func (c *Client) SearchMarketplace(params MarketplaceSearchParams) (*Marketplace
 	if params.Limit > 0 {
 		v.Set("limit", strconv.Itoa(params.Limit))
 	}
+	if len(params.Tags) > 0 {
+		v.Set("tags", strings.Join(params.Tags, ","))
+	}
+	if params.AccessTier != "" {
+		v.Set("access_tier", params.AccessTier)
+	}
+	if params.DataType != "" {
+		v.Set("data_type", params.DataType)
+	}
+	if params.PriceMin != nil {
+		v.Set("price_min", strconv.FormatInt(*params.PriceMin, 10))
+	}
+	if params.PriceMax != nil {
+		v.Set("price_max", strconv.FormatInt(*params.PriceMax, 10))
+	}
+	if params.OwnerID != 0 {
+		v.Set("owner_id", strconv.FormatInt(params.OwnerID, 10))
+	}
+	if params.MinRating > 0 {
+		v.Set("min_rating", strconv.FormatFloat(params.MinRating, 'f', -1, 64))
+	}
+	if params.Health != "" {
+		v.Set("health", params.Health)
+	}
 
 	path := "/v1/marketplace"
 	if qs := v.Encode(); qs != "" {
 		path += "?" + qs
 	}
 
-	respBody, _, err := c.doRequest("GET", path, nil)
+	respBody, _, err := c.doRequest(context.Background(), "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -415,9 +659,46 @@ func (c *Client) SearchMarketplace(params MarketplaceSearchParams) (*Marketplace
 	return &result, nil
 }
 
+func (p MarketplaceSearchParams) validate() error {
+	for _, t := range p.Tags {
+		if t == "" || strings.Contains(t, ",") {
+			return fmt.Errorf("integrat: invalid marketplace tag %q", t)
+		}
+	}
+	switch p.AccessTier {
+	case "", "open", "gated", "private":
+	default:
+		return fmt.Errorf("integrat: unknown access tier %q (want open, gated or private)", p.AccessTier)
+	}
+	switch p.DataType {
+	case "", "basic", "medium", "complex":
+	default:
+		return fmt.Errorf("integrat: unknown data type %q (want basic, medium or complex)", p.DataType)
+	}
+	switch p.Health {
+	case "", HealthUp, HealthDegraded, HealthDown:
+	default:
+		return fmt.Errorf("integrat: unknown health %q (want up, degraded or down)", p.Health)
+	}
+	if p.PriceMin != nil && *p.PriceMin < 0 || p.PriceMax != nil && *p.PriceMax < 0 {
+		return fmt.Errorf("integrat: price filter must be >= 0")
+	}
+	if p.PriceMin != nil && p.PriceMax != nil && *p.PriceMin > *p.PriceMax {
+		return fmt.Errorf("integrat: price_min %d > price_max %d", *p.PriceMin, *p.PriceMax)
+	}
+	if p.MinRating != 0 && (p.MinRating < 1 || p.MinRating > 5) {
+		return fmt.Errorf("integrat: min_rating must be within 1–5, got %v", p.MinRating)
+	}
+	return nil
+}
+
 // GetPluginBySlug возвращает полную информацию о плагине из маркетплейса.
 func (c *Client) GetPluginBySlug(slug string) (*PluginDetail, error) {
-	respBody, _, err := c.doRequest("GET", "/v1/marketplace/"+slug, nil)
+	return c.pluginBySlug(context.Background(), slug)
+}
+
+func (c *Client) pluginBySlug(ctx context.Context, slug string) (*PluginDetail, error) {
+	respBody, _, err := c.doRequest(ctx, "GET", "/v1/marketplace/"+slug, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -432,13 +713,25 @@ func (c *Client) GetPluginBySlug(slug string) (*PluginDetail, error) {
 
 // Health проверяет доступность API.
 func (c *Client) Health() error {
-	resp, err := c.HTTPClient.Get(c.BaseURL + "/health")
+	req, err := http.NewRequest("GET", c.BaseURL+"/health", nil)
 	if err != nil {
 		return fmt.Errorf("integrat: health check: %w", err)
 	}
+	call := &CallInfo{Method: "GET", Path: "/health"}
+	req, finish := c.observe(req, call)
+	res := &CallResult{}
+	defer func() { finish(res) }()
+
+	resp, err := c.do(req, call)
+	if err != nil {
+		res.Err = fmt.Errorf("integrat: health check: %w", err)
+		return res.Err
+	}
 	defer resp.Body.Close()
+	res.StatusCode = resp.StatusCode
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("integrat: health check returned %d", resp.StatusCode)
+		res.Err = fmt.Errorf("integrat: health check returned %d", resp.StatusCode)
+		return res.Err
 	}
 	return nil
 }
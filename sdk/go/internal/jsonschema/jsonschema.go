@@ -0,0 +1,400 @@
+// Package jsonschema — проверка значений по подмножеству JSON Schema
+// (draft-07), которого достаточно для params_schema, config_fields и
+// response_schema.
+//
+// Поддерживаются ключевые слова: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// minimum, maximum, pattern; default — через ApplyDefaults. Остальные
+// (title, description, examples, format, ...) игнорируются.
+//
+// Схема и значение — результат json.Unmarshal или yaml.Unmarshal в any:
+// map[string]any, []any, string, bool, nil и числа любых типов.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Коды ошибок (Error.Code).
+const (
+	CodeRequired      = "required"
+	CodeUnknownField  = "unknown_field"
+	CodeInvalidType   = "invalid_type"
+	CodeInvalidOption = "invalid_option" // enum, const
+	CodeOutOfRange    = "out_of_range"   // minimum/maximum, min/maxLength, min/maxItems
+	CodePattern       = "pattern_mismatch"
+	CodeInvalidSchema = "invalid_schema" // ошибка самой схемы (Check)
+)
+
+// Error — ошибка проверки. Path — путь к значению ("items[0].name"),
+// пустой для корня. Message — по-русски, MessageEN — то же по-английски.
+type Error struct {
+	Path      string
+	Code      string
+	Message   string
+	MessageEN string
+}
+
+func (e Error) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// StringEN — String с английским сообщением.
+func (e Error) StringEN() string {
+	if e.Path == "" {
+		return e.MessageEN
+	}
+	return e.Path + ": " + e.MessageEN
+}
+
+var validTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// ── Проверка схемы ──────────────────────────────────────────────────────
+
+// Check проверяет саму схему: допустимые type, структуру properties,
+// required и items, числовые ограничения и компилируемость pattern.
+func Check(schema map[string]any) []Error {
+	var errs []Error
+	checkSchema(schema, "", &errs)
+	return errs
+}
+
+func checkSchema(s map[string]any, path string, errs *[]Error) {
+	add := func(key, ru, en string) {
+		*errs = append(*errs, Error{Path: join(path, key), Code: CodeInvalidSchema, Message: ru, MessageEN: en})
+	}
+
+	if t, ok := s["type"]; ok {
+		types, ok := typeList(t)
+		if !ok {
+			add("type", "ожидается строка или массив строк", "must be a string or an array of strings")
+		}
+		for _, name := range types {
+			if !validTypes[name] {
+				add("type", fmt.Sprintf("недопустимое значение %q", name), fmt.Sprintf("unknown type %q", name))
+			}
+		}
+	}
+
+	if e, ok := s["enum"]; ok {
+		if list, ok := e.([]any); !ok || len(list) == 0 {
+			add("enum", "ожидается непустой массив", "must be a non-empty array")
+		}
+	}
+
+	if p, ok := s["properties"]; ok {
+		props, ok := p.(map[string]any)
+		if !ok {
+			add("properties", "ожидается объект", "must be an object")
+		}
+		for _, name := range sortedKeys(props) {
+			sub, ok := props[name].(map[string]any)
+			if !ok {
+				add("properties."+name, "ожидается схема (объект)", "must be a schema (object)")
+				continue
+			}
+			checkSchema(sub, join(path, "properties."+name), errs)
+		}
+	}
+
+	if r, ok := s["required"]; ok {
+		if _, ok := stringList(r); !ok {
+			add("required", "ожидается массив строк", "must be an array of strings")
+		}
+	}
+
+	switch ap := s["additionalProperties"].(type) {
+	case nil, bool:
+	case map[string]any:
+		checkSchema(ap, join(path, "additionalProperties"), errs)
+	default:
+		add("additionalProperties", "ожидается boolean или схема", "must be a boolean or a schema")
+	}
+
+	if it, ok := s["items"]; ok {
+		sub, ok := it.(map[string]any)
+		if !ok {
+			add("items", "ожидается схема (объект)", "must be a schema (object)")
+		} else {
+			checkSchema(sub, join(path, "items"), errs)
+		}
+	}
+
+	for _, key := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems"} {
+		if v, ok := s[key]; ok {
+			if _, ok := toFloat(v); !ok {
+				add(key, "ожидается число", "must be a number")
+			}
+		}
+	}
+
+	if p, ok := s["pattern"]; ok {
+		ps, ok := p.(string)
+		if !ok {
+			add("pattern", "ожидается строка", "must be a string")
+		} else if _, err := regexp.Compile(ps); err != nil {
+			add("pattern", fmt.Sprintf("невалидное регулярное выражение: %v", err), fmt.Sprintf("invalid regular expression: %v", err))
+		}
+	}
+}
+
+// ── Проверка значения ───────────────────────────────────────────────────
+
+// Validate проверяет значение v по схеме. Схема должна пройти Check —
+// некорректные ключевые слова пропускаются.
+func Validate(schema map[string]any, v any) []Error {
+	var errs []Error
+	validate(schema, v, "", &errs)
+	return errs
+}
+
+func validate(s map[string]any, v any, path string, errs *[]Error) {
+	add := func(code, ru, en string) {
+		*errs = append(*errs, Error{Path: path, Code: code, Message: ru, MessageEN: en})
+	}
+
+	if t, ok := s["type"]; ok {
+		types, _ := typeList(t)
+		if len(types) > 0 && !matchesAny(types, v) {
+			want := strings.Join(types, " | ")
+			add(CodeInvalidType, fmt.Sprintf("ожидается %s, получено %s", want, TypeOf(v)),
+				fmt.Sprintf("must be %s, got %s", want, TypeOf(v)))
+			return
+		}
+	}
+
+	if e, ok := s["enum"].([]any); ok {
+		found := false
+		for _, candidate := range e {
+			if equal(candidate, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add(CodeInvalidOption, "значение не входит в enum", "value is not one of the enum values")
+		}
+	}
+
+	if c, ok := s["const"]; ok && !equal(c, v) {
+		add(CodeInvalidOption, fmt.Sprintf("ожидается %v", c), fmt.Sprintf("must be %v", c))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		validateObject(s, val, path, errs)
+
+	case []any:
+		if n, ok := toFloat(s["minItems"]); ok && float64(len(val)) < n {
+			add(CodeOutOfRange, fmt.Sprintf("минимум %g элементов, получено %d", n, len(val)),
+				fmt.Sprintf("must have at least %g items, got %d", n, len(val)))
+		}
+		if n, ok := toFloat(s["maxItems"]); ok && float64(len(val)) > n {
+			add(CodeOutOfRange, fmt.Sprintf("максимум %g элементов, получено %d", n, len(val)),
+				fmt.Sprintf("must have at most %g items, got %d", n, len(val)))
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range val {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+
+	case string:
+		length := len([]rune(val))
+		if n, ok := toFloat(s["minLength"]); ok && float64(length) < n {
+			add(CodeOutOfRange, fmt.Sprintf("минимальная длина %g, получено %d", n, length),
+				fmt.Sprintf("must be at least %g characters, got %d", n, length))
+		}
+		if n, ok := toFloat(s["maxLength"]); ok && float64(length) > n {
+			add(CodeOutOfRange, fmt.Sprintf("максимальная длина %g, получено %d", n, length),
+				fmt.Sprintf("must be at most %g characters, got %d", n, length))
+		}
+		if p, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(val) {
+				add(CodePattern, fmt.Sprintf("не соответствует pattern %q", p), fmt.Sprintf("does not match pattern %q", p))
+			}
+		}
+
+	default:
+		if f, ok := toFloat(val); ok {
+			if n, ok := toFloat(s["minimum"]); ok && f < n {
+				add(CodeOutOfRange, fmt.Sprintf("должно быть >= %g", n), fmt.Sprintf("must be >= %g", n))
+			}
+			if n, ok := toFloat(s["maximum"]); ok && f > n {
+				add(CodeOutOfRange, fmt.Sprintf("должно быть <= %g", n), fmt.Sprintf("must be <= %g", n))
+			}
+		}
+	}
+}
+
+func validateObject(s map[string]any, obj map[string]any, path string, errs *[]Error) {
+	required, _ := stringList(s["required"])
+	for _, name := range required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, Error{Path: join(path, name), Code: CodeRequired, Message: "обязательное поле", MessageEN: "required"})
+		}
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	for _, name := range sortedKeys(obj) {
+		if sub, ok := props[name].(map[string]any); ok {
+			validate(sub, obj[name], join(path, name), errs)
+			continue
+		}
+		switch ap := s["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				*errs = append(*errs, Error{Path: join(path, name), Code: CodeUnknownField, Message: "неизвестное поле", MessageEN: "unknown field"})
+			}
+		case map[string]any:
+			validate(ap, obj[name], join(path, name), errs)
+		}
+	}
+}
+
+// ── Значения по умолчанию ───────────────────────────────────────────────
+
+// ApplyDefaults подставляет default из properties в объект obj (и во
+// вложенные объекты, которые в нём уже есть). obj изменяется на месте.
+func ApplyDefaults(schema map[string]any, obj map[string]any) {
+	props, _ := schema["properties"].(map[string]any)
+	for _, name := range sortedKeys(props) {
+		sub, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		v, present := obj[name]
+		if !present {
+			if def, ok := sub["default"]; ok {
+				obj[name] = def
+			}
+			continue
+		}
+		if nested, ok := v.(map[string]any); ok {
+			ApplyDefaults(sub, nested)
+		}
+	}
+}
+
+// ── Хелперы ─────────────────────────────────────────────────────────────
+
+// TypeOf возвращает имя JSON-типа значения.
+func TypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		if f, ok := toFloat(val); ok {
+			if f == math.Trunc(f) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func matchesAny(types []string, v any) bool {
+	actual := TypeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeList(t any) ([]string, bool) {
+	switch val := t.(type) {
+	case string:
+		return []string{val}, true
+	case []any:
+		return stringList(val)
+	}
+	return nil, false
+}
+
+func stringList(v any) ([]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+// equal сравнивает значения с учётом того, что числа из YAML и JSON
+// приходят разными типами.
+func equal(a, b any) bool {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA || okB {
+		return okA && okB && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,168 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func mustJSON(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func hasPath(errs []Error, path string) bool {
+	for _, e := range errs {
+		if e.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+const channelsSchema = `{
+  "type": "array",
+  "minItems": 1,
+  "items": {
+    "type": "object",
+    "required": ["username"],
+    "additionalProperties": false,
+    "properties": {
+      "username": {"type": "string", "pattern": "^[a-z0-9_]+$"},
+      "limit": {"type": "integer", "minimum": 1, "maximum": 100}
+    }
+  }
+}`
+
+func TestCheck_Valid(t *testing.T) {
+	if errs := Check(mustJSON(t, channelsSchema)); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCheck_Invalid(t *testing.T) {
+	errs := Check(mustJSON(t, `{
+	  "type": "list",
+	  "required": "name",
+	  "properties": {"a": {"type": "string", "pattern": "("}, "b": 1},
+	  "minimum": "zero"
+	}`))
+	for _, path := range []string{"type", "required", "properties.a.pattern", "properties.b", "minimum"} {
+		if !hasPath(errs, path) {
+			t.Errorf("expected error at %q, got: %v", path, errs)
+		}
+	}
+}
+
+func TestValidate_OK(t *testing.T) {
+	v := decode(t, `[{"username":"durov","limit":10},{"username":"telegram"}]`)
+	if errs := Validate(mustJSON(t, channelsSchema), v); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	v := decode(t, `[{"limit":0},{"username":"Bad Name","extra":true},{"username":"ok","limit":1.5}]`)
+	errs := Validate(mustJSON(t, channelsSchema), v)
+	for _, path := range []string{"[0].username", "[0].limit", "[1].username", "[1].extra", "[2].limit"} {
+		if !hasPath(errs, path) {
+			t.Errorf("expected error at %q, got: %v", path, errs)
+		}
+	}
+}
+
+func TestValidate_TypeMismatch(t *testing.T) {
+	errs := Validate(mustJSON(t, channelsSchema), decode(t, `{"username":"x"}`))
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "array") {
+		t.Errorf("errs = %v", errs)
+	}
+}
+
+func TestValidate_Codes(t *testing.T) {
+	schema := mustJSON(t, `{"type":"object","required":["q"],"additionalProperties":false,"properties":{
+	  "q":{"type":"string","pattern":"^[a-z]+$"},
+	  "n":{"type":"integer","maximum":5},
+	  "tags":{"type":"array","minItems":1},
+	  "kind":{"enum":["a","b"]}}}`)
+	errs := Validate(schema, decode(t, `{"n":9,"tags":[],"kind":"c","x":1,"q":"Go"}`))
+	want := map[string]string{
+		"kind: value is not one of the enum values": CodeInvalidOption,
+		"n: must be <= 5":                         CodeOutOfRange,
+		"q: does not match pattern \"^[a-z]+$\"":  CodePattern,
+		"tags: must have at least 1 items, got 0": CodeOutOfRange,
+		"x: unknown field":                        CodeUnknownField,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errs = %v", errs)
+	}
+	for _, e := range errs {
+		if code, ok := want[e.StringEN()]; !ok || code != e.Code {
+			t.Errorf("unexpected %q (code %q)", e.StringEN(), e.Code)
+		}
+	}
+
+	if errs := Validate(schema, decode(t, `{}`)); len(errs) != 1 || errs[0].Code != CodeRequired || errs[0].StringEN() != "q: required" {
+		t.Errorf("required: %v", errs)
+	}
+	if errs := Check(map[string]any{"type": "list"}); len(errs) != 1 || errs[0].Code != CodeInvalidSchema || errs[0].StringEN() != `type: unknown type "list"` {
+		t.Errorf("check: %+v", errs)
+	}
+}
+
+func TestValidate_EnumAndYAMLNumbers(t *testing.T) {
+	// Схема из YAML: числа приходят как int.
+	schema := map[string]any{"enum": []any{1, 2, 3}}
+	if errs := Validate(schema, float64(2)); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs := Validate(schema, float64(4)); len(errs) != 1 {
+		t.Errorf("errs = %v, want enum error", errs)
+	}
+}
+
+func TestValidate_MinMaxLength(t *testing.T) {
+	schema := map[string]any{"type": "string", "minLength": 2, "maxLength": 3}
+	if errs := Validate(schema, "ёж"); len(errs) != 0 {
+		t.Errorf("unexpected errors for 2 runes: %v", errs)
+	}
+	if errs := Validate(schema, "abcd"); len(errs) != 1 {
+		t.Errorf("errs = %v, want maxLength error", errs)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	schema := mustJSON(t, `{
+	  "type": "object",
+	  "properties": {
+	    "limit": {"type": "integer", "default": 50},
+	    "sort": {"type": "string", "default": "date"},
+	    "filter": {"type": "object", "properties": {"lang": {"default": "ru"}}},
+	    "extra": {"type": "object", "properties": {"x": {"default": 1}}}
+	  }
+	}`)
+	obj := map[string]any{"sort": "views", "filter": map[string]any{}}
+	ApplyDefaults(schema, obj)
+
+	if obj["limit"] != float64(50) || obj["sort"] != "views" {
+		t.Errorf("obj = %v", obj)
+	}
+	if f := obj["filter"].(map[string]any); f["lang"] != "ru" {
+		t.Errorf("filter = %v", f)
+	}
+	if _, ok := obj["extra"]; ok {
+		t.Error("absent nested object without default was created")
+	}
+}
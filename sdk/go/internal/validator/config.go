@@ -0,0 +1,484 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/plagness/Integrat/sdk/go/internal/jsonschema"
+)
+
+// ── Валидация значений конфигурации ─────────────────────────────────────
+
+// Коды ошибок значений конфигурации — стабильны и не зависят от языка
+// сообщения.
+const (
+	CodeRequired      = "required"
+	CodeUnknownField  = "unknown_field"
+	CodeInvalidType   = "invalid_type"
+	CodeInvalidURL    = "invalid_url"
+	CodePattern       = "pattern_mismatch"
+	CodeOutOfRange    = "out_of_range"
+	CodeInvalidOption = "invalid_option"
+	CodeInvalidJSON   = "invalid_json"
+	CodeSchema        = "schema_mismatch"
+	CodeCondition     = "invalid_condition"
+)
+
+// FieldError — ошибка значения одного поля конфигурации.
+type FieldError struct {
+	Field     string // slug поля
+	Code      string // CodeRequired, CodeInvalidType, ...
+	Message   string // текст для пользователя
+	MessageEN string // тот же текст на английском (ошибки SDK)
+}
+
+func (e FieldError) String() string { return e.Field + ": " + e.Message }
+
+// ConfigResult — результат проверки значений конфигурации.
+type ConfigResult struct {
+	Values map[string]any // нормализованные значения с применёнными default
+	Errors []FieldError
+}
+
+// OK возвращает true если нет ошибок.
+func (r *ConfigResult) OK() bool { return len(r.Errors) == 0 }
+
+func (r *ConfigResult) addError(field string, is *issue) {
+	r.Errors = append(r.Errors, FieldError{Field: field, Code: is.code, Message: is.ru, MessageEN: is.en})
+}
+
+// issue — ошибка значения без привязки к полю.
+type issue struct {
+	code, ru, en string
+}
+
+func newIssue(code, ru, en string) *issue { return &issue{code: code, ru: ru, en: en} }
+
+func typeIssue(want, wantEN string, v any) *issue {
+	return newIssue(CodeInvalidType,
+		fmt.Sprintf("ожидается %s, получено %s", want, typeName(v)),
+		fmt.Sprintf("must be %s, got %s", wantEN, typeNameEN(v)))
+}
+
+// ValidateConfig проверяет значения конфигурации по config_fields спецификации.
+// См. ValidateConfigValues.
+func ValidateConfig(spec *Spec, values map[string]any) *ConfigResult {
+	return ValidateConfigValues(spec.ConfigFields, values)
+}
+
+// ValidateConfigValues проверяет значения по определениям полей:
+//   - required — значение задано (или есть default);
+//   - number, integer и boolean — приведение из строк ("7", "true"),
+//     для чисел — границы min/max;
+//   - select и multiselect — значения входят в options;
+//   - url — абсолютный http(s) URL; pattern — для строковых типов;
+//   - json — строка или структура, проверяется по schema поля;
+//   - visible_if — скрытые поля пропускаются, их значения отбрасываются;
+//   - required_if — поле обязательно, если условие истинно;
+//   - default подставляется для незаданных полей;
+//   - поля, которых нет в определениях, — ошибка.
+//
+// Используется provider SDK, локальным gateway и бэкендом Mini App.
+func ValidateConfigValues(fields []ConfigFieldDef, values map[string]any) *ConfigResult {
+	r := &ConfigResult{Values: make(map[string]any, len(fields))}
+	known := make(map[string]bool, len(fields))
+	index := make(map[string]int, len(fields))
+	for i, f := range fields {
+		known[f.Slug] = true
+		index[f.Slug] = i
+	}
+
+	// Поля с условиями проверяются после полей, от которых зависят.
+	for _, i := range evalOrder(fields) {
+		f := fields[i]
+
+		visible, err := evalCond(f.VisibleIf, r.Values, true)
+		if err != nil {
+			r.addError(f.Slug, newIssue(CodeCondition, fmt.Sprintf("visible_if: %v", err), fmt.Sprintf("invalid visible_if: %v", err)))
+			continue
+		}
+		if !visible {
+			continue // скрытое поле: значение не сохраняется
+		}
+		required, err := evalCond(f.RequiredIf, r.Values, false)
+		if err != nil {
+			r.addError(f.Slug, newIssue(CodeCondition, fmt.Sprintf("required_if: %v", err), fmt.Sprintf("invalid required_if: %v", err)))
+			continue
+		}
+		required = required || f.Required
+
+		v, present := values[f.Slug]
+		if !present || isEmptyValue(v) {
+			if f.Default != nil {
+				v = f.Default
+			} else {
+				if required {
+					r.addError(f.Slug, newIssue(CodeRequired, "обязательное поле", "required"))
+				}
+				continue
+			}
+		}
+
+		norm, is := coerceConfigValue(f, v)
+		if is != nil {
+			r.addError(f.Slug, is)
+			continue
+		}
+		r.Values[f.Slug] = norm
+	}
+	sort.SliceStable(r.Errors, func(a, b int) bool {
+		return index[r.Errors[a].Field] < index[r.Errors[b].Field]
+	})
+
+	var unknown []string
+	for k := range values {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		r.addError(k, newIssue(CodeUnknownField, "неизвестное поле", "unknown field"))
+	}
+
+	return r
+}
+
+// evalCond вычисляет условие; пустое выражение даёт def.
+func evalCond(expr string, values map[string]any, def bool) (bool, error) {
+	if expr == "" {
+		return def, nil
+	}
+	cond, err := ParseCond(expr)
+	if err != nil {
+		return false, err
+	}
+	return cond.Eval(values), nil
+}
+
+// evalOrder возвращает индексы полей так, что зависимости visible_if/required_if
+// идут раньше зависимых полей. Порядок объявления сохраняется, где возможно;
+// циклы (их отсекает Validate) разрываются.
+func evalOrder(fields []ConfigFieldDef) []int {
+	index := make(map[string]int, len(fields))
+	for i, f := range fields {
+		index[f.Slug] = i
+	}
+	done := make([]bool, len(fields))
+	inStack := make([]bool, len(fields))
+	order := make([]int, 0, len(fields))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if done[i] || inStack[i] {
+			return
+		}
+		inStack[i] = true
+		for _, expr := range []string{fields[i].VisibleIf, fields[i].RequiredIf} {
+			if expr == "" {
+				continue
+			}
+			if cond, err := ParseCond(expr); err == nil {
+				for _, ref := range cond.Refs() {
+					if j, ok := index[ref]; ok {
+						visit(j)
+					}
+				}
+			}
+		}
+		inStack[i] = false
+		done[i] = true
+		order = append(order, i)
+	}
+	for i := range fields {
+		visit(i)
+	}
+	return order
+}
+
+func isEmptyValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []any:
+		return len(val) == 0
+	case []string:
+		return len(val) == 0
+	}
+	return false
+}
+
+// coerceConfigValue приводит значение к типу поля. Возвращает ошибку или
+// nil. Значение secret в тексте ошибки не выводится.
+func coerceConfigValue(f ConfigFieldDef, v any) (any, *issue) {
+	switch f.Type {
+	case "string", "text", "secret":
+		s, ok := v.(string)
+		if !ok {
+			return nil, typeIssue("строка", "a string", v)
+		}
+		return s, checkPattern(f, s)
+
+	case "url":
+		s, ok := v.(string)
+		if !ok {
+			return nil, typeIssue("строка", "a string", v)
+		}
+		s = strings.TrimSpace(s)
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, newIssue(CodeInvalidURL, "ожидается URL вида http(s)://host/...", "must be an absolute http(s) URL")
+		}
+		return s, checkPattern(f, s)
+
+	case "number":
+		n, ok := toFloat(v)
+		if !ok {
+			return nil, typeIssue("число", "a number", v)
+		}
+		return n, checkBounds(f, n)
+
+	case "integer":
+		if i, ok := exactInt(v); ok {
+			return i, checkBounds(f, float64(i))
+		}
+		n, ok := toFloat(v)
+		if !ok || n != math.Trunc(n) {
+			return nil, typeIssue("целое число", "an integer", v)
+		}
+		// float64(math.MaxInt64) == 2^63 — уже за пределами int64.
+		if n < math.MinInt64 || n >= math.MaxInt64 {
+			return nil, newIssue(CodeOutOfRange, "вне диапазона int64", "out of int64 range")
+		}
+		return int64(n), checkBounds(f, n)
+
+	case "boolean":
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+			if err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, typeIssue("boolean", "a boolean", v)
+
+	case "select":
+		s, ok := v.(string)
+		if !ok {
+			return nil, typeIssue("строка", "a string", v)
+		}
+		return s, checkOption(f, s)
+
+	case "multiselect":
+		items, ok := toStrings(v)
+		if !ok {
+			return nil, typeIssue("массив строк", "an array of strings", v)
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			if is := checkOption(f, item); is != nil {
+				return nil, is
+			}
+			out[i] = item
+		}
+		return out, nil
+
+	case "json":
+		if s, ok := v.(string); ok {
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, newIssue(CodeInvalidJSON, fmt.Sprintf("невалидный JSON: %v", err), fmt.Sprintf("invalid JSON: %v", err))
+			}
+		}
+		if f.Schema.Kind == 0 {
+			return v, nil
+		}
+		schema, err := nodeToMap(&f.Schema)
+		if err != nil {
+			return nil, newIssue(CodeSchema, fmt.Sprintf("невалидная schema: %v", err), fmt.Sprintf("invalid schema: %v", err))
+		}
+		if errs := jsonschema.Validate(schema, v); len(errs) > 0 {
+			msgs := make([]string, len(errs))
+			paths := make([]string, len(errs))
+			for i, e := range errs {
+				msgs[i] = e.String()
+				paths[i] = e.Path
+				if paths[i] == "" {
+					paths[i] = "(root)"
+				}
+			}
+			return nil, newIssue(CodeSchema, strings.Join(msgs, "; "),
+				"does not match schema at "+strings.Join(paths, ", "))
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+func checkPattern(f ConfigFieldDef, s string) *issue {
+	if f.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(f.Pattern)
+	if err != nil || !re.MatchString(s) {
+		return newIssue(CodePattern,
+			fmt.Sprintf("не соответствует pattern %q", f.Pattern),
+			fmt.Sprintf("does not match pattern %q", f.Pattern))
+	}
+	return nil
+}
+
+func checkBounds(f ConfigFieldDef, n float64) *issue {
+	if f.Min != nil && n < *f.Min {
+		return newIssue(CodeOutOfRange, fmt.Sprintf("должно быть >= %g", *f.Min), fmt.Sprintf("must be >= %g", *f.Min))
+	}
+	if f.Max != nil && n > *f.Max {
+		return newIssue(CodeOutOfRange, fmt.Sprintf("должно быть <= %g", *f.Max), fmt.Sprintf("must be <= %g", *f.Max))
+	}
+	return nil
+}
+
+func checkOption(f ConfigFieldDef, s string) *issue {
+	if len(f.Options) == 0 {
+		return nil
+	}
+	for _, opt := range f.Options {
+		if opt.Value == s {
+			return nil
+		}
+	}
+	opts := optionValues(f.Options)
+	return newIssue(CodeInvalidOption,
+		fmt.Sprintf("значение %q не входит в options (%s)", s, opts),
+		fmt.Sprintf("%q is not one of the options (%s)", s, opts))
+}
+
+// toStrings приводит []any, []string и строку через запятую к []string.
+func toStrings(v any) ([]string, bool) {
+	switch val := v.(type) {
+	case []string:
+		return val, true
+	case []any:
+		out := make([]string, len(val))
+		for i, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out[i] = s
+		}
+		return out, true
+	case string:
+		var out []string
+		for _, part := range strings.Split(val, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// toFloat приводит числа Go/JSON/YAML и числовые строки к float64.
+// NaN, бесконечности и числа вне диапазона float64 не принимаются.
+func toFloat(v any) (float64, bool) {
+	f, ok := anyFloat(v)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+func anyFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// exactInt приводит целые типы и целочисленные строки к int64 без потери
+// точности float64. Значения вне int64 не принимаются.
+func exactInt(v any) (int64, bool) {
+	switch i := v.(type) {
+	case int:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case json.Number:
+		x, err := i.Int64()
+		return x, err == nil
+	case string:
+		x, err := strconv.ParseInt(strings.TrimSpace(i), 10, 64)
+		return x, err == nil
+	}
+	return 0, false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case string:
+		return "строка"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int32, int64, uint64, json.Number:
+		return "число"
+	case []any:
+		return "массив"
+	case map[string]any:
+		return "объект"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeNameEN(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int32, int64, uint64, json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func optionValues(opts []ConfigOptionDef) string {
+	vals := make([]string, len(opts))
+	for i, o := range opts {
+		vals[i] = o.Value
+	}
+	return strings.Join(vals, ", ")
+}
@@ -0,0 +1,483 @@
+package validator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ── Helpers ─────────────────────────────────────────────────────────────
+
+func hasFieldError(r *ConfigResult, field string) bool {
+	for _, e := range r.Errors {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+const configSpecYaml = `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+config_fields:
+  - slug: channel
+    label: Channel
+    type: string
+    required: true
+  - slug: backfill_days
+    label: Days
+    type: number
+    default: 7
+  - slug: notify
+    label: Notify
+    type: boolean
+  - slug: mode
+    label: Mode
+    type: select
+    required: true
+    default: fast
+    options:
+      - value: fast
+        label: Fast
+      - value: slow
+        label: Slow
+`
+
+// ── ValidateConfig ──────────────────────────────────────────────────────
+
+func TestValidateConfig_DefaultsAndCoercion(t *testing.T) {
+	spec := mustParse(t, configSpecYaml)
+	r := ValidateConfig(spec, map[string]any{
+		"channel": "durov",
+		"notify":  "true",
+	})
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if r.Values["backfill_days"] != float64(7) {
+		t.Errorf("backfill_days = %#v, want default 7", r.Values["backfill_days"])
+	}
+	if r.Values["notify"] != true {
+		t.Errorf("notify = %#v, want true", r.Values["notify"])
+	}
+	if r.Values["mode"] != "fast" {
+		t.Errorf("mode = %#v, want default fast", r.Values["mode"])
+	}
+}
+
+func TestValidateConfig_NumberFromString(t *testing.T) {
+	spec := mustParse(t, configSpecYaml)
+	r := ValidateConfig(spec, map[string]any{"channel": "x", "backfill_days": " 30 "})
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if r.Values["backfill_days"] != float64(30) {
+		t.Errorf("backfill_days = %#v", r.Values["backfill_days"])
+	}
+}
+
+func TestValidateConfig_Errors(t *testing.T) {
+	spec := mustParse(t, configSpecYaml)
+	r := ValidateConfig(spec, map[string]any{
+		"channel":       "",
+		"backfill_days": "week",
+		"notify":        "maybe",
+		"mode":          "turbo",
+		"unknown":       1,
+	})
+	for _, field := range []string{"channel", "backfill_days", "notify", "mode", "unknown"} {
+		if !hasFieldError(r, field) {
+			t.Errorf("expected error for %q, got: %v", field, r.Errors)
+		}
+	}
+	if len(r.Errors) != 5 {
+		t.Errorf("errors = %d, want 5: %v", len(r.Errors), r.Errors)
+	}
+}
+
+func TestValidateConfig_StringTypeMismatch(t *testing.T) {
+	spec := mustParse(t, configSpecYaml)
+	r := ValidateConfig(spec, map[string]any{"channel": 42})
+	if !hasFieldError(r, "channel") {
+		t.Errorf("expected channel type error, got: %v", r.Errors)
+	}
+}
+
+func TestValidateConfig_NoFields(t *testing.T) {
+	spec := mustParse(t, validMinimal)
+	if r := ValidateConfig(spec, nil); !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+	if r := ValidateConfig(spec, map[string]any{"x": 1}); !hasFieldError(r, "x") {
+		t.Errorf("expected unknown field error, got: %v", r.Errors)
+	}
+}
+
+// ── Расширенные типы полей ──────────────────────────────────────────────
+
+const richConfigYaml = `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+config_fields:
+  - slug: api_key
+    label: API key
+    type: secret
+    pattern: "^sk_[a-z0-9]+$"
+  - slug: notes
+    label: Notes
+    type: text
+  - slug: webhook
+    label: Webhook
+    type: url
+  - slug: backfill_days
+    label: Days
+    type: integer
+    min: 1
+    max: 90
+    default: 7
+  - slug: topics
+    label: Topics
+    type: multiselect
+    options:
+      - value: news
+        label: News
+      - value: tech
+        label: Tech
+  - slug: channels
+    label: Channels
+    type: json
+    schema:
+      type: array
+      items:
+        type: object
+        required: [username]
+        properties:
+          username:
+            type: string
+`
+
+func TestValidate_RichConfigFieldTypes(t *testing.T) {
+	r := Validate(mustParse(t, richConfigYaml))
+	if !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+}
+
+func TestValidateConfig_RichTypesOK(t *testing.T) {
+	spec := mustParse(t, richConfigYaml)
+	r := ValidateConfig(spec, map[string]any{
+		"api_key":       "sk_abc123",
+		"notes":         "line1\nline2",
+		"webhook":       "https://example.com/hook",
+		"backfill_days": "30",
+		"topics":        "news, tech",
+		"channels":      `[{"username":"durov"}]`,
+	})
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if r.Values["backfill_days"] != int64(30) {
+		t.Errorf("backfill_days = %#v", r.Values["backfill_days"])
+	}
+	if topics, _ := r.Values["topics"].([]any); len(topics) != 2 {
+		t.Errorf("topics = %#v", r.Values["topics"])
+	}
+	if ch, _ := r.Values["channels"].([]any); len(ch) != 1 {
+		t.Errorf("channels = %#v", r.Values["channels"])
+	}
+}
+
+func TestValidateConfig_RichTypesErrors(t *testing.T) {
+	spec := mustParse(t, richConfigYaml)
+	r := ValidateConfig(spec, map[string]any{
+		"api_key":       "hunter2-very-secret",
+		"webhook":       "example.com/hook",
+		"backfill_days": 7.5,
+		"topics":        []any{"news", "sport"},
+		"channels":      []any{map[string]any{"name": "durov"}},
+	})
+	for _, field := range []string{"api_key", "webhook", "backfill_days", "topics", "channels"} {
+		if !hasFieldError(r, field) {
+			t.Errorf("expected error for %q, got: %v", field, r.Errors)
+		}
+	}
+	for _, e := range r.Errors {
+		if strings.Contains(e.Message, "hunter2") {
+			t.Errorf("secret value echoed in error: %s", e)
+		}
+	}
+
+	r = ValidateConfig(spec, map[string]any{"backfill_days": 91})
+	if !hasFieldError(r, "backfill_days") {
+		t.Errorf("expected max error, got: %v", r.Errors)
+	}
+}
+
+func TestValidate_RichConfigFieldDefErrors(t *testing.T) {
+	_, r := ValidateBytes([]byte(`
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+config_fields:
+  - slug: token
+    label: Token
+    type: secret
+    default: abc
+  - slug: days
+    label: Days
+    type: integer
+    min: 10
+    max: 1
+  - slug: name
+    label: Name
+    type: boolean
+    pattern: "^x$"
+  - slug: re
+    label: Re
+    type: string
+    pattern: "("
+  - slug: data
+    label: Data
+    type: json
+    schema:
+      type: list
+  - slug: limit
+    label: Limit
+    type: integer
+    default: "many"
+`))
+	for _, want := range []string{
+		"config_fields[0].default",
+		"config_fields[1]: min",
+		"config_fields[2].pattern",
+		"config_fields[3].pattern",
+		"config_fields[4].schema.type",
+		"config_fields[5].default",
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+// ── Условные поля ───────────────────────────────────────────────────────
+
+const conditionalConfigYaml = `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+config_fields:
+  - slug: api_key
+    label: API key
+    type: secret
+    group: Подключение
+    visible_if: mode == remote
+    required_if: mode == remote
+  - slug: mode
+    label: Mode
+    type: select
+    group: Подключение
+    order: 1
+    default: local
+    options:
+      - value: local
+        label: Local
+      - value: remote
+        label: Remote
+  - slug: endpoint
+    label: Endpoint
+    type: url
+    group: Подключение
+    order: 2
+    required_if: mode == remote && !api_key
+`
+
+func TestValidate_ConditionalConfigFields(t *testing.T) {
+	r := Validate(mustParse(t, conditionalConfigYaml))
+	if !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+}
+
+func TestValidateConfig_VisibleIf(t *testing.T) {
+	spec := mustParse(t, conditionalConfigYaml)
+
+	// mode=local (default): api_key скрыт, его значение отбрасывается.
+	r := ValidateConfig(spec, map[string]any{"api_key": "sk_1"})
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if _, ok := r.Values["api_key"]; ok {
+		t.Error("hidden api_key kept in values")
+	}
+
+	// mode=remote: api_key виден и обязателен.
+	r = ValidateConfig(spec, map[string]any{"mode": "remote"})
+	if !hasFieldError(r, "api_key") {
+		t.Errorf("expected api_key required error, got: %v", r.Errors)
+	}
+	if r.Errors[0].Field != "api_key" {
+		t.Errorf("errors not in declaration order: %v", r.Errors)
+	}
+
+	r = ValidateConfig(spec, map[string]any{"mode": "remote", "api_key": "sk_1"})
+	if !r.OK() || r.Values["api_key"] != "sk_1" {
+		t.Errorf("values = %v, errors = %v", r.Values, r.Errors)
+	}
+}
+
+func TestValidate_ConditionalConfigFieldErrors(t *testing.T) {
+	_, r := ValidateBytes([]byte(`
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+config_fields:
+  - slug: a
+    label: A
+    type: string
+    visible_if: b == x
+  - slug: b
+    label: B
+    type: string
+    required_if: a
+  - slug: c
+    label: C
+    type: string
+    visible_if: nope == 1
+    order: -1
+  - slug: d
+    label: D
+    type: string
+    required_if: d
+  - slug: e
+    label: E
+    type: string
+    visible_if: "e =="
+`))
+	for _, want := range []string{
+		"цикл зависимостей visible_if/required_if: a → b → a",
+		`config_fields[2].visible_if: неизвестное поле "nope"`,
+		"config_fields[2].order",
+		"config_fields[3].required_if: поле ссылается само на себя",
+		"config_fields[4].visible_if",
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+func TestValidateConfigValues_Codes(t *testing.T) {
+	min := 1.0
+	fields := []ConfigFieldDef{
+		{Slug: "name", Type: "string", Required: true},
+		{Slug: "limit", Type: "integer", Min: &min},
+		{Slug: "site", Type: "url"},
+	}
+	r := ValidateConfigValues(fields, map[string]any{"limit": 0, "site": "ftp://x", "extra": true})
+	want := map[string]string{
+		"name":  CodeRequired,
+		"limit": CodeOutOfRange,
+		"site":  CodeInvalidURL,
+		"extra": CodeUnknownField,
+	}
+	if len(r.Errors) != len(want) {
+		t.Fatalf("errors = %v", r.Errors)
+	}
+	for _, e := range r.Errors {
+		if e.Code != want[e.Field] {
+			t.Errorf("%s: code = %q, want %q", e.Field, e.Code, want[e.Field])
+		}
+		if e.Message == "" || e.MessageEN == "" || strings.ContainsAny(e.MessageEN, "абвгдеёжзийклмнопрстуфхцчшщъыьэюя") {
+			t.Errorf("%s: message = %q, en = %q", e.Field, e.Message, e.MessageEN)
+		}
+	}
+}
+
+func TestValidateConfigValues_NonFiniteNumbers(t *testing.T) {
+	min, max := 0.0, 10.0
+	fields := []ConfigFieldDef{
+		{Slug: "ratio", Type: "number", Min: &min, Max: &max},
+		{Slug: "count", Type: "integer"},
+	}
+	for _, v := range []any{"NaN", "nan", "Inf", "-Infinity", "1e400", math.NaN(), math.Inf(1)} {
+		r := ValidateConfigValues(fields, map[string]any{"ratio": v})
+		if len(r.Errors) != 1 || r.Errors[0].Code != CodeInvalidType {
+			t.Errorf("ratio = %v: errors = %v, want invalid_type", v, r.Errors)
+		}
+		r = ValidateConfigValues(fields, map[string]any{"count": v})
+		if len(r.Errors) != 1 || r.Errors[0].Code != CodeInvalidType {
+			t.Errorf("count = %v: errors = %v, want invalid_type", v, r.Errors)
+		}
+	}
+}
+
+func TestValidateConfigValues_IntegerRange(t *testing.T) {
+	fields := []ConfigFieldDef{{Slug: "count", Type: "integer"}}
+	for _, v := range []any{"9223372036854775808", 1e19, -1e19, float64(math.MaxInt64)} {
+		r := ValidateConfigValues(fields, map[string]any{"count": v})
+		if len(r.Errors) != 1 || r.Errors[0].Code != CodeOutOfRange {
+			t.Errorf("count = %v: errors = %v, want out_of_range", v, r.Errors)
+		}
+	}
+
+	for v, want := range map[any]int64{
+		"9223372036854775807": math.MaxInt64,
+		int64(math.MinInt64):  math.MinInt64,
+		"-42":                 -42,
+		float64(1 << 53):      1 << 53,
+	} {
+		r := ValidateConfigValues(fields, map[string]any{"count": v})
+		if !r.OK() || r.Values["count"] != want {
+			t.Errorf("count = %v: value = %#v, errors = %v", v, r.Values["count"], r.Errors)
+		}
+	}
+}
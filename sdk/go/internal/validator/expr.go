@@ -0,0 +1,296 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ── Условия visible_if / required_if ────────────────────────────────────
+//
+// Грамматика:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" expr ")" | cond
+//	cond    = slug [ ("==" | "!=") value | "in" "(" value { "," value } ")" ]
+//	value   = "строка в кавычках" | слово
+//
+// Одиночный slug истинен, если значение задано и не равно false, 0, "" или [].
+// Для multiselect "slug == x" истинно, если x выбран.
+
+// Cond — разобранное условие.
+type Cond interface {
+	// Eval вычисляет условие по нормализованным значениям конфигурации.
+	Eval(values map[string]any) bool
+	// Refs возвращает slug'и полей, на которые ссылается условие.
+	Refs() []string
+}
+
+// ParseCond разбирает выражение visible_if / required_if.
+func ParseCond(s string) (Cond, error) {
+	p := &condParser{toks: tokenize(s)}
+	if len(p.toks) == 0 {
+		return nil, fmt.Errorf("пустое выражение")
+	}
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("неожиданный токен %q", p.toks[p.pos].text)
+	}
+	return c, nil
+}
+
+type condOr struct{ left, right Cond }
+
+func (c condOr) Eval(v map[string]any) bool { return c.left.Eval(v) || c.right.Eval(v) }
+func (c condOr) Refs() []string             { return append(c.left.Refs(), c.right.Refs()...) }
+
+type condAnd struct{ left, right Cond }
+
+func (c condAnd) Eval(v map[string]any) bool { return c.left.Eval(v) && c.right.Eval(v) }
+func (c condAnd) Refs() []string             { return append(c.left.Refs(), c.right.Refs()...) }
+
+type condNot struct{ inner Cond }
+
+func (c condNot) Eval(v map[string]any) bool { return !c.inner.Eval(v) }
+func (c condNot) Refs() []string             { return c.inner.Refs() }
+
+// condCmp — сравнение поля со списком значений. op: "", "==", "!=", "in".
+type condCmp struct {
+	field  string
+	op     string
+	values []string
+}
+
+func (c condCmp) Refs() []string { return []string{c.field} }
+
+func (c condCmp) Eval(v map[string]any) bool {
+	val, ok := v[c.field]
+	if c.op == "" {
+		return ok && truthy(val)
+	}
+	match := false
+	for _, want := range c.values {
+		if valueMatches(val, want) {
+			match = true
+			break
+		}
+	}
+	if c.op == "!=" {
+		return !match
+	}
+	return match
+}
+
+func truthy(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case []any:
+		return len(val) > 0
+	}
+	if f, ok := toFloat(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+func valueMatches(v any, want string) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case []any:
+		for _, item := range val {
+			if fmt.Sprint(item) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return fmt.Sprint(v) == want
+}
+
+// ── Лексер и парсер ─────────────────────────────────────────────────────
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(s string) []token {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"),
+			strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="):
+			toks = append(toks, token{text: s[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')' || c == ',':
+			toks = append(toks, token{text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				toks = append(toks, token{text: s[i:]})
+				return toks
+			}
+			toks = append(toks, token{text: s[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			if j == i {
+				j++ // неизвестный символ — отдельный токен, парсер вернёт ошибку
+			}
+			toks = append(toks, token{text: s[i:j]})
+			i = j
+		}
+	}
+	return toks
+}
+
+func isWordByte(c byte) bool {
+	r := rune(c)
+	return c >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r) || c == '_' || c == '-' || c == '.'
+}
+
+type condParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *condParser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *condParser) accept(op string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *condParser) parseOr() (Cond, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = condOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (Cond, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = condAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (Cond, error) {
+	if p.accept("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return condNot{inner}, nil
+	}
+	if p.accept("(") {
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("ожидается \")\"")
+		}
+		return c, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *condParser) parseCmp() (Cond, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("неожиданный конец выражения")
+	}
+	if t.quoted || !isWordByte(t.text[0]) || t.text == "in" {
+		return nil, fmt.Errorf("ожидается slug поля, получено %q", t.text)
+	}
+	p.pos++
+	c := condCmp{field: t.text}
+
+	switch {
+	case p.accept("=="):
+		c.op = "=="
+	case p.accept("!="):
+		c.op = "!="
+	case p.accept("in"):
+		c.op = "in"
+		if !p.accept("(") {
+			return nil, fmt.Errorf("%s in: ожидается \"(\"", c.field)
+		}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+			if p.accept(")") {
+				return c, nil
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("%s in: ожидается \",\" или \")\"", c.field)
+			}
+		}
+	default:
+		return c, nil
+	}
+
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	c.values = []string{v}
+	return c, nil
+}
+
+func (p *condParser) parseValue() (string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("ожидается значение")
+	}
+	if !t.quoted && (len(t.text) == 0 || !isWordByte(t.text[0])) {
+		return "", fmt.Errorf("ожидается значение, получено %q", t.text)
+	}
+	p.pos++
+	return t.text, nil
+}
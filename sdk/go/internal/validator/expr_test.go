@@ -0,0 +1,68 @@
+package validator
+
+import "testing"
+
+func TestParseCond_Eval(t *testing.T) {
+	values := map[string]any{
+		"mode":    "remote",
+		"enabled": true,
+		"limit":   float64(0),
+		"topics":  []any{"news", "tech"},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`mode == remote`, true},
+		{`mode == "local"`, false},
+		{`mode != 'local'`, true},
+		{`mode in (local, remote)`, true},
+		{`enabled`, true},
+		{`!enabled`, false},
+		{`limit`, false},
+		{`missing`, false},
+		{`missing != x`, true},
+		{`topics == tech`, true},
+		{`enabled && mode == local || topics == news`, true},
+		{`enabled && (mode == local || limit)`, false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCond(tt.expr)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if got := c.Eval(values); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCond_Refs(t *testing.T) {
+	c, err := ParseCond(`mode == remote && !(region in (eu, us) || debug)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := c.Refs()
+	if len(refs) != 3 || refs[0] != "mode" || refs[1] != "region" || refs[2] != "debug" {
+		t.Errorf("refs = %v", refs)
+	}
+}
+
+func TestParseCond_Errors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`mode ==`,
+		`mode == remote &&`,
+		`(mode == remote`,
+		`mode in remote`,
+		`mode in (a b)`,
+		`"mode" == x`,
+		`mode == "unterminated`,
+		`mode = remote`,
+	} {
+		if _, err := ParseCond(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ── Валидация pricing ───────────────────────────────────────────────────
+
+var validFreeTierPeriods = map[string]bool{
+	"day": true, "week": true, "month": true,
+}
+
+var validPlanPeriods = map[string]bool{
+	"month": true, "year": true, "once": true,
+}
+
+func validatePricing(spec *Spec, r *Result) {
+	p := spec.Pricing
+	if p == nil {
+		return
+	}
+
+	if ft := p.FreeTier; ft != nil {
+		if ft.Requests <= 0 {
+			r.addError("pricing.free_tier.requests: должен быть > 0 (получено %d)", ft.Requests)
+		}
+		if ft.Period != "" && !validFreeTierPeriods[ft.Period] {
+			r.addError("pricing.free_tier.period: недопустимое значение %q (допустимо: day, week, month)", ft.Period)
+		}
+	}
+
+	for _, dt := range sortedPriceKeys(p.PerCall) {
+		if !validDataTypes[dt] {
+			r.addError("pricing.per_call.%s: неизвестный data_type (допустимо: basic, medium, complex)", dt)
+		}
+		if p.PerCall[dt] < 0 {
+			r.addError("pricing.per_call.%s: цена должна быть >= 0 (получено %d)", dt, p.PerCall[dt])
+		}
+	}
+
+	endpoints := make(map[string]EndpointDef, len(spec.Endpoints))
+	for _, ep := range spec.Endpoints {
+		endpoints[ep.Slug] = ep
+	}
+	for _, slug := range sortedPriceKeys(p.Endpoints) {
+		if _, ok := endpoints[slug]; !ok {
+			r.addError("pricing.endpoints.%s: эндпоинт не найден", slug)
+		}
+		if p.Endpoints[slug] < 0 {
+			r.addError("pricing.endpoints.%s: цена должна быть >= 0 (получено %d)", slug, p.Endpoints[slug])
+		}
+	}
+
+	// Эндпоинт без цены при заданном per_call молча окажется бесплатным.
+	if len(p.PerCall) > 0 {
+		for i, ep := range spec.Endpoints {
+			if _, ok := p.Endpoints[ep.Slug]; ok {
+				continue
+			}
+			if ep.DataType == "" {
+				r.addWarning("endpoints[%d]: нет ни data_type, ни цены в pricing.endpoints — запросы бесплатны", i)
+			} else if _, ok := p.PerCall[ep.DataType]; !ok {
+				r.addWarning("endpoints[%d]: data_type=%s отсутствует в pricing.per_call — запросы бесплатны", i, ep.DataType)
+			}
+		}
+	}
+
+	validatePlans(p.Plans, endpoints, r)
+}
+
+func validatePlans(plans []PlanDef, endpoints map[string]EndpointDef, r *Result) {
+	slugs := make(map[string]int)
+	for i, plan := range plans {
+		prefix := fmt.Sprintf("pricing.plans[%d]", i)
+
+		if plan.Slug == "" {
+			r.addError("%s.slug: обязательное поле", prefix)
+		} else {
+			if !slugRe.MatchString(plan.Slug) {
+				r.addError("%s.slug: невалидный формат %q", prefix, plan.Slug)
+			}
+			if prev, ok := slugs[plan.Slug]; ok {
+				r.addError("%s.slug: дубликат %q (первое появление: pricing.plans[%d])", prefix, plan.Slug, prev)
+			}
+			slugs[plan.Slug] = i
+		}
+
+		if plan.Name == "" {
+			r.addError("%s.name: обязательное поле", prefix)
+		}
+		if plan.Price <= 0 {
+			r.addError("%s.price: должна быть > 0 Stars (получено %d)", prefix, plan.Price)
+		}
+		if plan.Period != "" && !validPlanPeriods[plan.Period] {
+			r.addError("%s.period: недопустимое значение %q (допустимо: month, year, once)", prefix, plan.Period)
+		}
+		if plan.Included < 0 {
+			r.addError("%s.included: должен быть >= 0 (получено %d)", prefix, plan.Included)
+		}
+		if plan.Period == "once" && plan.Included == 0 {
+			r.addWarning("%s: разовая покупка без included даёт бессрочный безлимит", prefix)
+		}
+
+		for j, slug := range plan.Endpoints {
+			if _, ok := endpoints[slug]; !ok {
+				r.addError("%s.endpoints[%d]: эндпоинт %q не найден", prefix, j, slug)
+			}
+		}
+	}
+}
+
+func sortedPriceKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
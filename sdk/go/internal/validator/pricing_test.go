@@ -0,0 +1,118 @@
+package validator
+
+import "testing"
+
+const pricingSpecYaml = `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: messages.fetch
+    name: Fetch
+    path: /fetch
+    access: open
+    data_type: basic
+  - slug: messages.search
+    name: Search
+    path: /search
+    access: open
+    data_type: complex
+`
+
+func TestValidatePricing(t *testing.T) {
+	spec := mustParse(t, pricingSpecYaml+`
+pricing:
+  free_tier:
+    requests: 100
+    period: month
+  per_call:
+    basic: 0
+    complex: 5
+  endpoints:
+    messages.search: 10
+  plans:
+    - slug: pro
+      name: Pro
+      price: 500
+      included: 10000
+    - slug: search-pack
+      name: Пакет поиска
+      price: 100
+      period: once
+      included: 1000
+      endpoints: [messages.search]
+`)
+	if r := Validate(spec); !r.OK() || len(r.Warnings) != 0 {
+		t.Errorf("unexpected errors: %v, warnings: %v", r.Errors, r.Warnings)
+	}
+}
+
+func TestValidatePricing_Invalid(t *testing.T) {
+	spec := mustParse(t, pricingSpecYaml+`
+pricing:
+  free_tier:
+    requests: 0
+    period: hour
+  per_call:
+    huge: 1
+    basic: -1
+  endpoints:
+    missing: 3
+  plans:
+    - slug: pro
+      price: 0
+      period: weekly
+      included: -5
+      endpoints: [nope]
+    - slug: pro
+      name: Pro 2
+      price: 10
+`)
+	r := Validate(spec)
+	for _, want := range []string{
+		"pricing.free_tier.requests: должен быть > 0",
+		`pricing.free_tier.period: недопустимое значение "hour"`,
+		"pricing.per_call.huge: неизвестный data_type",
+		"pricing.per_call.basic: цена должна быть >= 0",
+		"pricing.endpoints.missing: эндпоинт не найден",
+		"pricing.plans[0].name: обязательное поле",
+		"pricing.plans[0].price: должна быть > 0",
+		`pricing.plans[0].period: недопустимое значение "weekly"`,
+		"pricing.plans[0].included: должен быть >= 0",
+		`pricing.plans[0].endpoints[0]: эндпоинт "nope" не найден`,
+		`pricing.plans[1].slug: дубликат "pro"`,
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+func TestValidatePricing_Warnings(t *testing.T) {
+	spec := mustParse(t, pricingSpecYaml+`
+pricing:
+  per_call:
+    basic: 1
+  plans:
+    - slug: lifetime
+      name: Навсегда
+      price: 1000
+      period: once
+`)
+	r := Validate(spec)
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	for _, want := range []string{
+		"endpoints[1]: data_type=complex отсутствует в pricing.per_call",
+		"pricing.plans[0]: разовая покупка без included",
+	} {
+		if !hasWarning(r, want) {
+			t.Errorf("expected warning containing %q, got: %v", want, r.Warnings)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 // Пакет validator — валидация integrat.yaml спецификаций.
 // Проверяет структуру, обязательные поля, форматы slug, enum-значения,
-// уникальность идентификаторов и корректность params_schema.
+// уникальность идентификаторов и корректность params_schema и response_schema.
 package validator
 
 import (
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/plagness/Integrat/sdk/go/internal/jsonschema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,23 +22,26 @@ type Spec struct {
 	Provider     ProviderDef      `yaml:"provider"`
 	Endpoints    []EndpointDef    `yaml:"endpoints"`
 	ConfigFields []ConfigFieldDef `yaml:"config_fields"`
+	RateLimit    *RateLimitDef    `yaml:"rate_limit"` // общий лимит на все эндпоинты плагина
+	Pricing      *PricingDef      `yaml:"pricing"`
 }
 
 // PluginDef — секция plugin.
 type PluginDef struct {
-	Slug        string `yaml:"slug"`
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Version     string `yaml:"version"`
-	Homepage    string `yaml:"homepage"`
-	Icon        string `yaml:"icon"`
+	Slug        string   `yaml:"slug"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Version     string   `yaml:"version"`
+	Homepage    string   `yaml:"homepage"`
+	Icon        string   `yaml:"icon"`
+	Tags        []string `yaml:"tags"` // теги для фильтра маркетплейса
 }
 
 // ProviderDef — секция provider.
 type ProviderDef struct {
 	BaseURL    string   `yaml:"base_url"`
 	HealthPath string   `yaml:"health_path"`
-	ProxyMode  string   `yaml:"proxy_mode"`
+	ProxyMode  string   `yaml:"proxy_mode"` // proxy (по умолчанию), redirect, push
 	Auth       *AuthDef `yaml:"auth"`
 }
 
@@ -45,19 +50,78 @@ type AuthDef struct {
 	Type   string `yaml:"type"`
 	Env    string `yaml:"env"`
 	Header string `yaml:"header"`
+
+	// type: hmac — подпись запросов gateway общим секретом из Env.
+	Algorithm     string   `yaml:"algorithm"`      // sha256 (по умолчанию), sha512
+	SignedHeaders []string `yaml:"signed_headers"` // заголовки, входящие в подпись
+	Tolerance     *int     `yaml:"tolerance"`      // допустимое расхождение часов, секунды (по умолчанию 300)
+
+	// type: oauth2_client_credentials — токен с token_url (RFC 6749, 4.4).
+	TokenURL        string   `yaml:"token_url"`
+	Scopes          []string `yaml:"scopes"`
+	ClientIDEnv     string   `yaml:"client_id_env"`
+	ClientSecretEnv string   `yaml:"client_secret_env"`
+
+	// type: mtls — клиентский сертификат: PEM из переменных или пути к файлам.
+	CertEnv  string `yaml:"cert_env"`
+	KeyEnv   string `yaml:"key_env"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	CAFile   string `yaml:"ca_file"` // CA сервера провайдера; по умолчанию системные
 }
 
 // EndpointDef — определение одного эндпоинта.
 type EndpointDef struct {
-	Slug         string    `yaml:"slug"`
-	Name         string    `yaml:"name"`
-	Description  string    `yaml:"description"`
-	Path         string    `yaml:"path"`
-	Method       string    `yaml:"method"`
-	Access       string    `yaml:"access"`
-	CacheTTL     *int      `yaml:"cache_ttl"`
-	DataType     string    `yaml:"data_type"`
-	ParamsSchema yaml.Node `yaml:"params_schema"`
+	Slug           string        `yaml:"slug"`
+	Name           string        `yaml:"name"`
+	Description    string        `yaml:"description"`
+	Path           string        `yaml:"path"`
+	Method         string        `yaml:"method"`
+	Access         string        `yaml:"access"`
+	CacheTTL       *int          `yaml:"cache_ttl"`
+	DataType       string        `yaml:"data_type"`
+	Streaming      bool          `yaml:"streaming"`
+	Subscribe      bool          `yaml:"subscribe"` // обновления по SSE (text/event-stream)
+	ParamsSchema   yaml.Node     `yaml:"params_schema"`
+	ResponseSchema yaml.Node     `yaml:"response_schema"`
+	RateLimit      *RateLimitDef `yaml:"rate_limit"`
+}
+
+// RateLimitDef — ограничение частоты и параллельности запросов. Gateway
+// отвечает 429 при превышении.
+type RateLimitDef struct {
+	Requests    *int   `yaml:"requests"`    // запросов за window
+	Window      string `yaml:"window"`      // длительность окна: 1s, 1m, 1h (по умолчанию 1m)
+	Per         string `yaml:"per"`         // ключ лимита: chat, user, token (по умолчанию), global
+	Burst       *int   `yaml:"burst"`       // дополнительный запас сверх requests
+	Concurrency *int   `yaml:"concurrency"` // одновременных запросов на ключ
+}
+
+// PricingDef — цены плагина в Telegram Stars.
+type PricingDef struct {
+	FreeTier     *FreeTierDef   `yaml:"free_tier"`
+	PerCall      map[string]int `yaml:"per_call"`      // цена запроса по data_type
+	Endpoints    map[string]int `yaml:"endpoints"`     // цена запроса по slug эндпоинта; важнее per_call
+	ChargeCached bool           `yaml:"charge_cached"` // списывать и за ответы из кеша
+	Plans        []PlanDef      `yaml:"plans"`
+}
+
+// FreeTierDef — бесплатные запросы на пользователя за период.
+type FreeTierDef struct {
+	Requests int    `yaml:"requests"`
+	Period   string `yaml:"period"` // day, week, month (по умолчанию)
+}
+
+// PlanDef — тарифный план: подписка (period: month, year) или разовая
+// покупка (period: once).
+type PlanDef struct {
+	Slug        string   `yaml:"slug"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Price       int      `yaml:"price"`     // Stars за период
+	Period      string   `yaml:"period"`    // month (по умолчанию), year, once
+	Included    int      `yaml:"included"`  // запросов в период; 0 — без ограничения
+	Endpoints   []string `yaml:"endpoints"` // эндпоинты плана; пусто — все
 }
 
 // ConfigFieldDef — определение поля конфигурации.
@@ -70,9 +134,17 @@ type ConfigFieldDef struct {
 	Placeholder string            `yaml:"placeholder"`
 	Help        string            `yaml:"help"`
 	Options     []ConfigOptionDef `yaml:"options"`
+	Min         *float64          `yaml:"min"`         // number, integer
+	Max         *float64          `yaml:"max"`         // number, integer
+	Pattern     string            `yaml:"pattern"`     // string, text, secret, url
+	Schema      yaml.Node         `yaml:"schema"`      // json: JSON Schema значения
+	Group       string            `yaml:"group"`       // секция формы
+	Order       int               `yaml:"order"`       // порядок внутри секции
+	VisibleIf   string            `yaml:"visible_if"`  // условие показа поля
+	RequiredIf  string            `yaml:"required_if"` // условие обязательности
 }
 
-// ConfigOptionDef — вариант для type: select.
+// ConfigOptionDef — вариант для type: select и multiselect.
 type ConfigOptionDef struct {
 	Value string `yaml:"value"`
 	Label string `yaml:"label"`
@@ -102,6 +174,11 @@ func (r *Result) addWarning(format string, args ...any) {
 // slugRe — формат slug из JSON Schema: ^[a-z0-9][a-z0-9._-]*$
 var slugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
 
+// tagRe — формат тега плагина; maxTags — предел числа тегов.
+var tagRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+const maxTags = 10
+
 var validMethods = map[string]bool{
 	"GET": true, "POST": true, "PUT": true, "DELETE": true,
 }
@@ -115,11 +192,38 @@ var validDataTypes = map[string]bool{
 }
 
 var validAuthTypes = map[string]bool{
-	"bearer": true, "header": true, "none": true,
+	"bearer": true, "header": true, "hmac": true,
+	"oauth2_client_credentials": true, "mtls": true, "none": true,
+}
+
+// Режимы работы gateway с провайдером (provider.proxy_mode):
+//   - proxy — gateway проксирует каждый запрос на base_url + path;
+//   - redirect — gateway отвечает 307 на base_url + path, клиент идёт к провайдеру сам;
+//   - push — провайдер сам загружает снимки данных, gateway отдаёт последний снимок.
+var validProxyModes = map[string]bool{
+	"proxy": true, "redirect": true, "push": true,
+}
+
+var validHMACAlgorithms = map[string]bool{
+	"sha256": true, "sha512": true,
 }
 
+var validRateLimitKeys = map[string]bool{
+	"chat": true, "user": true, "token": true, "global": true,
+}
+
+// headerNameRe — имя HTTP-заголовка (token из RFC 9110).
+var headerNameRe = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
 var validConfigFieldTypes = map[string]bool{
-	"string": true, "number": true, "boolean": true, "select": true,
+	"string": true, "text": true, "secret": true, "url": true,
+	"number": true, "integer": true, "boolean": true,
+	"select": true, "multiselect": true, "json": true,
+}
+
+// Типы полей, к которым применим pattern.
+var patternFieldTypes = map[string]bool{
+	"string": true, "text": true, "secret": true, "url": true,
 }
 
 // ── API ─────────────────────────────────────────────────────────────────
@@ -140,6 +244,8 @@ func Validate(spec *Spec) *Result {
 	validateProvider(spec, r)
 	validateEndpoints(spec, r)
 	validateConfigFields(spec, r)
+	validateRateLimits(spec, r)
+	validatePricing(spec, r)
 	return r
 }
 
@@ -172,19 +278,36 @@ func validatePlugin(spec *Spec, r *Result) {
 	if p.Version == "" {
 		r.addError("plugin.version: обязательное поле")
 	}
+
+	if len(p.Tags) > maxTags {
+		r.addError("plugin.tags: не более %d тегов (получено %d)", maxTags, len(p.Tags))
+	}
+	seen := make(map[string]bool)
+	for i, t := range p.Tags {
+		if !tagRe.MatchString(t) {
+			r.addError("plugin.tags[%d]: невалидный формат %q (ожидается ^[a-z0-9][a-z0-9-]*$, до 32 символов)", i, t)
+		} else if seen[t] {
+			r.addWarning("plugin.tags[%d]: тег %q повторяется", i, t)
+		}
+		seen[t] = true
+	}
 }
 
 func validateProvider(spec *Spec, r *Result) {
 	prov := spec.Provider
 
-	if prov.BaseURL == "" {
+	if prov.BaseURL == "" && prov.ProxyMode != "push" {
 		r.addError("provider.base_url: обязательное поле")
 	}
+	validateProxyMode(spec, r)
 
 	if prov.Auth != nil {
 		if prov.Auth.Type != "" && !validAuthTypes[prov.Auth.Type] {
-			r.addError("provider.auth.type: недопустимое значение %q (допустимо: bearer, header, none)", prov.Auth.Type)
+			r.addError("provider.auth.type: недопустимое значение %q (допустимо: bearer, header, hmac, oauth2_client_credentials, mtls, none)", prov.Auth.Type)
 		}
+		validateHMACAuth(prov.Auth, r)
+		validateOAuth2Auth(prov.Auth, r)
+		validateMTLSAuth(prov.Auth, r)
 		if prov.Auth.Type == "header" && prov.Auth.Header == "" {
 			r.addWarning("provider.auth: type=header, но header не указан")
 		}
@@ -194,6 +317,138 @@ func validateProvider(spec *Spec, r *Result) {
 	}
 }
 
+func validateProxyMode(spec *Spec, r *Result) {
+	prov := spec.Provider
+	switch prov.ProxyMode {
+	case "", "proxy":
+	case "redirect":
+		// Клиент обращается к провайдеру напрямую: gateway не может ни
+		// подставить секрет, ни закешировать ответ.
+		if prov.Auth != nil && prov.Auth.Type != "" && prov.Auth.Type != "none" {
+			r.addWarning("provider.auth: type=%s не применяется при proxy_mode=redirect (клиент обращается к провайдеру напрямую)", prov.Auth.Type)
+		}
+		if strings.HasPrefix(prov.BaseURL, "http://") {
+			r.addWarning("provider.base_url: при proxy_mode=redirect клиенты получат адрес без TLS (http://)")
+		}
+		for i, ep := range spec.Endpoints {
+			if ep.CacheTTL != nil && *ep.CacheTTL > 0 {
+				r.addWarning("endpoints[%d]: proxy_mode=redirect, cache_ttl игнорируется (ответы идут мимо gateway)", i)
+			}
+			if ep.Subscribe {
+				r.addError("endpoints[%d].subscribe: несовместимо с proxy_mode=redirect (gateway не видит поток провайдера)", i)
+			}
+		}
+	case "push":
+		if prov.Auth != nil && prov.Auth.Type != "" && prov.Auth.Type != "none" {
+			r.addWarning("provider.auth: type=%s не применяется при proxy_mode=push (gateway не обращается к провайдеру)", prov.Auth.Type)
+		}
+		for i, ep := range spec.Endpoints {
+			prefix := fmt.Sprintf("endpoints[%d]", i)
+			if ep.Streaming {
+				r.addError("%s.streaming: несовместимо с proxy_mode=push (снимок отдаётся целиком)", prefix)
+			}
+			if ep.ParamsSchema.Kind != 0 {
+				r.addWarning("%s.params_schema: при proxy_mode=push параметры не влияют на снимок", prefix)
+			}
+		}
+	default:
+		r.addError("provider.proxy_mode: недопустимое значение %q (допустимо: proxy, redirect, push)", prov.ProxyMode)
+	}
+}
+
+func validateHMACAuth(a *AuthDef, r *Result) {
+	if a.Type != "hmac" {
+		if a.Algorithm != "" || len(a.SignedHeaders) > 0 || a.Tolerance != nil {
+			r.addWarning("provider.auth: algorithm, signed_headers и tolerance применимы только к type=hmac и игнорируются")
+		}
+		return
+	}
+
+	if a.Env == "" {
+		r.addError("provider.auth.env: обязательное поле для type=hmac (переменная с секретом подписи)")
+	}
+	if a.Algorithm != "" && !validHMACAlgorithms[a.Algorithm] {
+		r.addError("provider.auth.algorithm: недопустимое значение %q (допустимо: sha256, sha512)", a.Algorithm)
+	}
+	if a.Header != "" && !headerNameRe.MatchString(a.Header) {
+		r.addError("provider.auth.header: невалидное имя заголовка %q", a.Header)
+	}
+	if a.Tolerance != nil && *a.Tolerance <= 0 {
+		r.addError("provider.auth.tolerance: должен быть > 0 (получено %d)", *a.Tolerance)
+	}
+
+	seen := make(map[string]bool, len(a.SignedHeaders))
+	for i, h := range a.SignedHeaders {
+		prefix := fmt.Sprintf("provider.auth.signed_headers[%d]", i)
+		if !headerNameRe.MatchString(h) {
+			r.addError("%s: невалидное имя заголовка %q", prefix, h)
+			continue
+		}
+		key := strings.ToLower(h)
+		if seen[key] {
+			r.addError("%s: дубликат %q", prefix, h)
+		}
+		seen[key] = true
+	}
+}
+
+func validateOAuth2Auth(a *AuthDef, r *Result) {
+	if a.Type != "oauth2_client_credentials" {
+		if a.TokenURL != "" || len(a.Scopes) > 0 || a.ClientIDEnv != "" || a.ClientSecretEnv != "" {
+			r.addWarning("provider.auth: token_url, scopes, client_id_env и client_secret_env применимы только к type=oauth2_client_credentials и игнорируются")
+		}
+		return
+	}
+
+	if a.TokenURL == "" {
+		r.addError("provider.auth.token_url: обязательное поле для type=oauth2_client_credentials")
+	} else if !strings.HasPrefix(a.TokenURL, "https://") && !strings.HasPrefix(a.TokenURL, "http://") && !strings.HasPrefix(a.TokenURL, "${") {
+		r.addError("provider.auth.token_url: ожидается http(s) URL, получено %q", a.TokenURL)
+	} else if strings.HasPrefix(a.TokenURL, "http://") {
+		r.addWarning("provider.auth.token_url: секрет клиента передаётся без TLS (http://)")
+	}
+	if a.ClientIDEnv == "" {
+		r.addError("provider.auth.client_id_env: обязательное поле для type=oauth2_client_credentials")
+	}
+	if a.ClientSecretEnv == "" {
+		r.addError("provider.auth.client_secret_env: обязательное поле для type=oauth2_client_credentials")
+	}
+
+	seen := make(map[string]bool, len(a.Scopes))
+	for i, sc := range a.Scopes {
+		prefix := fmt.Sprintf("provider.auth.scopes[%d]", i)
+		switch {
+		case sc == "" || strings.ContainsAny(sc, " \t\n\"\\"):
+			r.addError("%s: невалидный scope %q", prefix, sc)
+		case seen[sc]:
+			r.addError("%s: дубликат %q", prefix, sc)
+		}
+		seen[sc] = true
+	}
+}
+
+func validateMTLSAuth(a *AuthDef, r *Result) {
+	if a.Type != "mtls" {
+		if a.CertEnv != "" || a.KeyEnv != "" || a.CertFile != "" || a.KeyFile != "" || a.CAFile != "" {
+			r.addWarning("provider.auth: cert_env, key_env, cert_file, key_file и ca_file применимы только к type=mtls и игнорируются")
+		}
+		return
+	}
+
+	fromEnv := a.CertEnv != "" || a.KeyEnv != ""
+	fromFile := a.CertFile != "" || a.KeyFile != ""
+	switch {
+	case fromEnv && fromFile:
+		r.addError("provider.auth: для type=mtls укажите либо cert_env/key_env, либо cert_file/key_file")
+	case fromEnv && (a.CertEnv == "" || a.KeyEnv == ""):
+		r.addError("provider.auth: для type=mtls cert_env и key_env указываются вместе")
+	case fromFile && (a.CertFile == "" || a.KeyFile == ""):
+		r.addError("provider.auth: для type=mtls cert_file и key_file указываются вместе")
+	case !fromEnv && !fromFile:
+		r.addError("provider.auth: для type=mtls обязательны cert_env/key_env или cert_file/key_file")
+	}
+}
+
 func validateEndpoints(spec *Spec, r *Result) {
 	if len(spec.Endpoints) == 0 {
 		r.addError("endpoints: минимум 1 эндпоинт обязателен")
@@ -220,7 +475,9 @@ func validateEndpoints(spec *Spec, r *Result) {
 			r.addError("%s.name: обязательное поле", prefix)
 		}
 		if ep.Path == "" {
-			r.addError("%s.path: обязательное поле", prefix)
+			if spec.Provider.ProxyMode != "push" {
+				r.addError("%s.path: обязательное поле", prefix)
+			}
 		} else if !strings.HasPrefix(ep.Path, "/") {
 			r.addError("%s.path: должен начинаться с / (получено %q)", prefix, ep.Path)
 		}
@@ -243,23 +500,101 @@ func validateEndpoints(spec *Spec, r *Result) {
 			r.addError("%s.cache_ttl: должен быть >= 0 (получено %d)", prefix, *ep.CacheTTL)
 		}
 
+		if ep.Streaming && ep.CacheTTL != nil && *ep.CacheTTL > 0 {
+			r.addWarning("%s: streaming=true, cache_ttl игнорируется (потоковые ответы не кешируются)", prefix)
+		}
+
 		validateParamsSchema(ep, prefix, r)
+		validateResponseSchema(ep, prefix, r)
 	}
 }
 
+func validateRateLimits(spec *Spec, r *Result) {
+	validateRateLimit(spec.RateLimit, "rate_limit", r)
+	for i, ep := range spec.Endpoints {
+		prefix := fmt.Sprintf("endpoints[%d].rate_limit", i)
+		validateRateLimit(ep.RateLimit, prefix, r)
+
+		// Лимит эндпоинта мягче общего с тем же ключом никогда не сработает.
+		pl, el := spec.RateLimit, ep.RateLimit
+		if pl == nil || el == nil || pl.per() != el.per() {
+			continue
+		}
+		if prate, ok := pl.rate(); ok {
+			if erate, ok := el.rate(); ok && erate > prate {
+				r.addWarning("%s: лимит мягче общего rate_limit и не сработает", prefix)
+			}
+		}
+	}
+}
+
+func validateRateLimit(rl *RateLimitDef, prefix string, r *Result) {
+	if rl == nil {
+		return
+	}
+	if rl.Requests == nil && rl.Concurrency == nil {
+		r.addError("%s: укажите requests и/или concurrency", prefix)
+	}
+	if rl.Requests != nil && *rl.Requests <= 0 {
+		r.addError("%s.requests: должен быть > 0 (получено %d)", prefix, *rl.Requests)
+	}
+	if rl.Window != "" {
+		if rl.Requests == nil {
+			r.addError("%s.window: применим только вместе с requests", prefix)
+		}
+		if d, err := time.ParseDuration(rl.Window); err != nil {
+			r.addError("%s.window: невалидная длительность %q (например 30s, 1m, 1h)", prefix, rl.Window)
+		} else if d < time.Second {
+			r.addError("%s.window: минимум 1s (получено %s)", prefix, rl.Window)
+		}
+	}
+	if rl.Burst != nil {
+		if rl.Requests == nil {
+			r.addError("%s.burst: применим только вместе с requests", prefix)
+		}
+		if *rl.Burst < 0 {
+			r.addError("%s.burst: должен быть >= 0 (получено %d)", prefix, *rl.Burst)
+		}
+	}
+	if rl.Concurrency != nil && *rl.Concurrency <= 0 {
+		r.addError("%s.concurrency: должен быть > 0 (получено %d)", prefix, *rl.Concurrency)
+	}
+	if rl.Per != "" && !validRateLimitKeys[rl.Per] {
+		r.addError("%s.per: недопустимое значение %q (допустимо: chat, user, token, global)", prefix, rl.Per)
+	}
+}
+
+func (rl *RateLimitDef) per() string {
+	if rl.Per == "" {
+		return "token"
+	}
+	return rl.Per
+}
+
+// rate — запросов в секунду; false, если лимит по частоте не задан или невалиден.
+func (rl *RateLimitDef) rate() (float64, bool) {
+	if rl.Requests == nil || *rl.Requests <= 0 {
+		return 0, false
+	}
+	window := time.Minute
+	if rl.Window != "" {
+		d, err := time.ParseDuration(rl.Window)
+		if err != nil || d < time.Second {
+			return 0, false
+		}
+		window = d
+	}
+	return float64(*rl.Requests) / window.Seconds(), true
+}
+
 func validateParamsSchema(ep EndpointDef, prefix string, r *Result) {
 	if ep.ParamsSchema.Kind == 0 {
 		return // нет params_schema — OK
 	}
 
 	// Конвертируем yaml.Node → map для проверки
-	var schema map[string]any
-	raw, err := yaml.Marshal(&ep.ParamsSchema)
+	schema, err := nodeToMap(&ep.ParamsSchema)
 	if err != nil {
-		r.addError("%s.params_schema: ошибка сериализации: %v", prefix, err)
-		return
-	}
-	if err := yaml.Unmarshal(raw, &schema); err != nil {
 		r.addError("%s.params_schema: невалидная структура: %v", prefix, err)
 		return
 	}
@@ -303,11 +638,51 @@ func validateConfigFields(spec *Spec, r *Result) {
 		if cf.Type == "" {
 			r.addError("%s.type: обязательное поле", prefix)
 		} else if !validConfigFieldTypes[cf.Type] {
-			r.addError("%s.type: недопустимое значение %q (допустимо: string, number, boolean, select)", prefix, cf.Type)
+			r.addError("%s.type: недопустимое значение %q (допустимо: string, text, secret, url, number, integer, boolean, select, multiselect, json)", prefix, cf.Type)
+		}
+
+		if (cf.Type == "select" || cf.Type == "multiselect") && len(cf.Options) == 0 {
+			r.addWarning("%s: type=%s, но options не указаны", prefix, cf.Type)
+		}
+
+		if cf.Min != nil || cf.Max != nil {
+			if cf.Type != "number" && cf.Type != "integer" {
+				r.addError("%s: min/max применимы только к number и integer", prefix)
+			} else if cf.Min != nil && cf.Max != nil && *cf.Min > *cf.Max {
+				r.addError("%s: min (%g) больше max (%g)", prefix, *cf.Min, *cf.Max)
+			}
+		}
+
+		if cf.Pattern != "" {
+			if !patternFieldTypes[cf.Type] {
+				r.addError("%s.pattern: применим только к string, text, secret, url", prefix)
+			} else if _, err := regexp.Compile(cf.Pattern); err != nil {
+				r.addError("%s.pattern: невалидное регулярное выражение: %v", prefix, err)
+			}
+		}
+
+		if cf.Schema.Kind != 0 {
+			if cf.Type != "json" {
+				r.addError("%s.schema: применима только к type=json", prefix)
+			} else if schema, err := nodeToMap(&cf.Schema); err != nil {
+				r.addError("%s.schema: невалидная структура: %v", prefix, err)
+			} else {
+				for _, e := range jsonschema.Check(schema) {
+					r.addError("%s.schema.%s", prefix, e)
+				}
+			}
 		}
 
-		if cf.Type == "select" && len(cf.Options) == 0 {
-			r.addWarning("%s: type=select, но options не указаны", prefix)
+		if cf.Order < 0 {
+			r.addError("%s.order: должен быть >= 0 (получено %d)", prefix, cf.Order)
+		}
+
+		if cf.Type == "secret" && cf.Default != nil {
+			r.addError("%s.default: secret не может иметь значение по умолчанию", prefix)
+		} else if cf.Default != nil && validConfigFieldTypes[cf.Type] {
+			if _, is := coerceConfigValue(cf, cf.Default); is != nil {
+				r.addError("%s.default: %s", prefix, is.ru)
+			}
 		}
 
 		for j, opt := range cf.Options {
@@ -320,4 +695,113 @@ func validateConfigFields(spec *Spec, r *Result) {
 			}
 		}
 	}
+
+	validateConfigConditions(spec.ConfigFields, slugs, r)
+}
+
+func validateResponseSchema(ep EndpointDef, prefix string, r *Result) {
+	if ep.ResponseSchema.Kind == 0 {
+		return // нет response_schema — OK
+	}
+
+	schema, err := nodeToMap(&ep.ResponseSchema)
+	if err != nil {
+		r.addError("%s.response_schema: невалидная структура: %v", prefix, err)
+		return
+	}
+	for _, e := range jsonschema.Check(schema) {
+		r.addError("%s.response_schema.%s", prefix, e)
+	}
+
+	if _, err := json.Marshal(schema); err != nil {
+		r.addError("%s.response_schema: не сериализуется в JSON: %v", prefix, err)
+	}
+}
+
+// nodeToMap конвертирует YAML-узел схемы в map.
+func nodeToMap(n *yaml.Node) (map[string]any, error) {
+	var m map[string]any
+	if err := n.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// validateConfigConditions проверяет visible_if/required_if: синтаксис,
+// ссылки на существующие поля и отсутствие циклов зависимостей.
+func validateConfigConditions(fields []ConfigFieldDef, slugs map[string]int, r *Result) {
+	deps := make(map[string][]string)
+	for i, cf := range fields {
+		prefix := fmt.Sprintf("config_fields[%d]", i)
+		for _, attr := range []struct{ name, expr string }{
+			{"visible_if", cf.VisibleIf},
+			{"required_if", cf.RequiredIf},
+		} {
+			if attr.expr == "" {
+				continue
+			}
+			cond, err := ParseCond(attr.expr)
+			if err != nil {
+				r.addError("%s.%s: %v", prefix, attr.name, err)
+				continue
+			}
+			for _, ref := range cond.Refs() {
+				switch _, ok := slugs[ref]; {
+				case ref == cf.Slug:
+					r.addError("%s.%s: поле ссылается само на себя", prefix, attr.name)
+				case !ok:
+					r.addError("%s.%s: неизвестное поле %q", prefix, attr.name, ref)
+				default:
+					deps[cf.Slug] = append(deps[cf.Slug], ref)
+				}
+			}
+		}
+	}
+
+	if cycle := findCycle(fields, deps); cycle != nil {
+		r.addError("config_fields: цикл зависимостей visible_if/required_if: %s", strings.Join(cycle, " → "))
+	}
+}
+
+// findCycle ищет цикл в графе зависимостей полей (DFS с раскраской).
+func findCycle(fields []ConfigFieldDef, deps map[string][]string) []string {
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make(map[string]int)
+	var stack []string
+
+	var visit func(slug string) []string
+	visit = func(slug string) []string {
+		color[slug] = grey
+		stack = append(stack, slug)
+		for _, dep := range deps[slug] {
+			switch color[dep] {
+			case grey:
+				for i, s := range stack {
+					if s == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case white:
+				if c := visit(dep); c != nil {
+					return c
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[slug] = black
+		return nil
+	}
+
+	for _, f := range fields {
+		if color[f.Slug] == white {
+			if c := visit(f.Slug); c != nil {
+				return c
+			}
+		}
+	}
+	return nil
 }
@@ -1042,3 +1042,602 @@ endpoints:
 		t.Errorf("all methods should be valid: %v", r.Errors)
 	}
 }
+
+// ── streaming ───────────────────────────────────────────────────────────
+
+func TestValidateEndpoints_Streaming(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    data_type: complex
+    streaming: true
+`)
+	r := Validate(spec)
+	if !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+	if !spec.Endpoints[0].Streaming {
+		t.Error("streaming = false, want true")
+	}
+	if len(r.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", r.Warnings)
+	}
+}
+
+func TestValidateEndpoints_StreamingWithCacheTTL(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    cache_ttl: 60
+    streaming: true
+`)
+	r := Validate(spec)
+	if !hasWarning(r, "cache_ttl игнорируется") {
+		t.Errorf("expected streaming cache_ttl warning, got: %v", r.Warnings)
+	}
+}
+
+func TestValidateResponseSchema_Valid(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    response_schema:
+      type: array
+      items:
+        type: object
+        required: [id, text]
+        properties:
+          id: { type: integer }
+          text: { type: string }
+`)
+	r := Validate(spec)
+	if !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+}
+
+func TestValidateResponseSchema_Invalid(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: T
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    response_schema:
+      type: list
+      items:
+        properties:
+          text: { type: string, pattern: "(" }
+  - slug: b
+    name: B
+    path: /b
+    access: open
+    response_schema: [1, 2]
+`)
+	r := Validate(spec)
+	for _, want := range []string{
+		"endpoints[0].response_schema.type",
+		"endpoints[0].response_schema.items.properties.text.pattern",
+		"endpoints[1].response_schema: невалидная структура",
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+func TestValidateProvider_HMACAuth(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: http://x
+  auth:
+    type: hmac
+    env: GATEWAY_SECRET
+    algorithm: sha512
+    signed_headers: [Content-Type, X-Request-ID]
+    tolerance: 120
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	if !r.OK() {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+	if a := spec.Provider.Auth; a.Algorithm != "sha512" || len(a.SignedHeaders) != 2 || *a.Tolerance != 120 {
+		t.Errorf("auth = %+v", a)
+	}
+}
+
+func TestValidateProvider_HMACAuthInvalid(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: http://x
+  auth:
+    type: hmac
+    algorithm: md5
+    header: "X Signature"
+    signed_headers: [Content-Type, content-type, "bad header"]
+    tolerance: 0
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	for _, want := range []string{
+		"provider.auth.env: обязательное поле",
+		"provider.auth.algorithm",
+		"provider.auth.header",
+		"provider.auth.tolerance",
+		"provider.auth.signed_headers[1]: дубликат",
+		"provider.auth.signed_headers[2]: невалидное имя",
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+func TestValidateProvider_HMACFieldsIgnoredWarning(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: http://x
+  auth:
+    type: bearer
+    env: TOKEN
+    algorithm: sha256
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	if !r.OK() || !hasWarning(r, "только к type=hmac") {
+		t.Errorf("errors = %v, warnings = %v", r.Errors, r.Warnings)
+	}
+}
+
+func TestValidateProvider_OAuth2Auth(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  auth:
+    type: oauth2_client_credentials
+    token_url: https://auth.example.com/oauth/token
+    scopes: [read:posts, read:channels]
+    client_id_env: EXAMPLE_CLIENT_ID
+    client_secret_env: EXAMPLE_CLIENT_SECRET
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	if !r.OK() || len(r.Warnings) != 0 {
+		t.Errorf("errors = %v, warnings = %v", r.Errors, r.Warnings)
+	}
+}
+
+func TestValidateProvider_OAuth2AuthInvalid(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  auth:
+    type: oauth2_client_credentials
+    token_url: auth.example.com/token
+    scopes: [read, read, "two words"]
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	for _, want := range []string{
+		"provider.auth.token_url: ожидается http(s) URL",
+		"provider.auth.client_id_env: обязательное поле",
+		"provider.auth.client_secret_env: обязательное поле",
+		"provider.auth.scopes[1]: дубликат",
+		"provider.auth.scopes[2]: невалидный scope",
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+}
+
+func TestValidateProvider_MTLSAuth(t *testing.T) {
+	for name, auth := range map[string]string{
+		"env":  "cert_env: CLIENT_CERT\n    key_env: CLIENT_KEY",
+		"file": "cert_file: /etc/integrat/client.pem\n    key_file: /etc/integrat/client.key\n    ca_file: /etc/integrat/ca.pem",
+	} {
+		spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  auth:
+    type: mtls
+    `+auth+`
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+		if r := Validate(spec); !r.OK() {
+			t.Errorf("%s: unexpected errors: %v", name, r.Errors)
+		}
+	}
+}
+
+func TestValidateProvider_MTLSAuthInvalid(t *testing.T) {
+	for auth, want := range map[string]string{
+		"":                                    "обязательны cert_env/key_env или cert_file/key_file",
+		"cert_env: C":                         "cert_env и key_env указываются вместе",
+		"key_file: k.pem":                     "cert_file и key_file указываются вместе",
+		"cert_env: C\n    cert_file: c.pem": "либо cert_env/key_env, либо cert_file/key_file",
+	} {
+		spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  auth:
+    type: mtls
+    `+auth+`
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+		if r := Validate(spec); !hasError(r, want) {
+			t.Errorf("auth %q: expected error containing %q, got: %v", auth, want, r.Errors)
+		}
+	}
+}
+
+func TestValidateProvider_ProxyMode(t *testing.T) {
+	for _, mode := range []string{"proxy", "redirect", "push"} {
+		spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  proxy_mode: `+mode+`
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+		if r := Validate(spec); !r.OK() {
+			t.Errorf("%s: unexpected errors: %v", mode, r.Errors)
+		}
+	}
+
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  proxy_mode: mirror
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	if r := Validate(spec); !hasError(r, `provider.proxy_mode: недопустимое значение "mirror"`) {
+		t.Errorf("expected proxy_mode error, got: %v", r.Errors)
+	}
+}
+
+func TestValidateProvider_ProxyModeRedirect(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: http://api.example.com
+  proxy_mode: redirect
+  auth:
+    type: bearer
+    env: TOKEN
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    cache_ttl: 60
+`)
+	r := Validate(spec)
+	if !r.OK() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	for _, want := range []string{
+		"type=bearer не применяется при proxy_mode=redirect",
+		"адрес без TLS",
+		"endpoints[0]: proxy_mode=redirect, cache_ttl игнорируется",
+	} {
+		if !hasWarning(r, want) {
+			t.Errorf("expected warning containing %q, got: %v", want, r.Warnings)
+		}
+	}
+}
+
+func TestValidateProvider_ProxyModePush(t *testing.T) {
+	// base_url и path не обязательны: провайдер сам загружает снимки.
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  proxy_mode: push
+endpoints:
+  - slug: a
+    name: A
+    access: open
+  - slug: b
+    name: B
+    access: open
+    streaming: true
+    params_schema:
+      type: object
+`)
+	r := Validate(spec)
+	if hasError(r, "base_url") || hasError(r, "path") {
+		t.Errorf("base_url/path must be optional for push: %v", r.Errors)
+	}
+	if !hasError(r, "endpoints[1].streaming: несовместимо с proxy_mode=push") {
+		t.Errorf("expected streaming error, got: %v", r.Errors)
+	}
+	if !hasWarning(r, "endpoints[1].params_schema: при proxy_mode=push") {
+		t.Errorf("expected params_schema warning, got: %v", r.Warnings)
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+rate_limit:
+  requests: 1000
+  window: 1h
+  per: chat
+endpoints:
+  - slug: messages.search
+    name: Search
+    path: /search
+    access: open
+    rate_limit:
+      requests: 10
+      window: 1m
+      per: chat
+      burst: 5
+      concurrency: 2
+  - slug: b
+    name: B
+    path: /b
+    access: open
+    rate_limit:
+      concurrency: 1
+`)
+	if r := Validate(spec); !r.OK() || len(r.Warnings) != 0 {
+		t.Errorf("unexpected errors: %v, warnings: %v", r.Errors, r.Warnings)
+	}
+}
+
+func TestValidateRateLimit_Invalid(t *testing.T) {
+	for block, want := range map[string]string{
+		"per: chat":                        "укажите requests и/или concurrency",
+		"requests: 0":                      "requests: должен быть > 0",
+		"requests: 5\n      window: soon":  `window: невалидная длительность "soon"`,
+		"requests: 5\n      window: 100ms": "window: минимум 1s",
+		"concurrency: 2\n      window: 1m": "window: применим только вместе с requests",
+		"concurrency: 2\n      burst: 3":   "burst: применим только вместе с requests",
+		"requests: 5\n      burst: -1":     "burst: должен быть >= 0",
+		"concurrency: 0":                   "concurrency: должен быть > 0",
+		"requests: 5\n      per: ip":       `per: недопустимое значение "ip"`,
+	} {
+		spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    rate_limit:
+      `+block+`
+`)
+		if r := Validate(spec); !hasError(r, want) {
+			t.Errorf("%q: expected error containing %q, got: %v", block, want, r.Errors)
+		}
+	}
+}
+
+func TestValidateRateLimit_LooserThanPlugin(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+rate_limit:
+  requests: 60
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    rate_limit:
+      requests: 10
+      window: 1s
+`)
+	r := Validate(spec)
+	if !hasWarning(r, "endpoints[0].rate_limit: лимит мягче общего") {
+		t.Errorf("expected warning, got: %v", r.Warnings)
+	}
+}
+
+func TestValidateEndpoint_Subscribe(t *testing.T) {
+	const tmpl = `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+provider:
+  base_url: https://api.example.com
+  proxy_mode: %s
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+    subscribe: true
+`
+	for _, mode := range []string{"proxy", "push"} {
+		r := Validate(mustParse(t, strings.Replace(tmpl, "%s", mode, 1)))
+		if !r.OK() {
+			t.Errorf("%s: unexpected errors: %v", mode, r.Errors)
+		}
+	}
+	r := Validate(mustParse(t, strings.Replace(tmpl, "%s", "redirect", 1)))
+	if !hasError(r, "endpoints[0].subscribe: несовместимо с proxy_mode=redirect") {
+		t.Errorf("expected error, got: %v", r.Errors)
+	}
+}
+
+func TestValidatePlugin_Tags(t *testing.T) {
+	spec := mustParse(t, `
+plugin:
+  slug: test
+  name: Test
+  description: D
+  version: "1.0"
+  tags: [telegram, channels, Telegram, telegram, "with space"]
+provider:
+  base_url: https://api.example.com
+endpoints:
+  - slug: a
+    name: A
+    path: /a
+    access: open
+`)
+	r := Validate(spec)
+	for _, want := range []string{
+		`plugin.tags[2]: невалидный формат "Telegram"`,
+		`plugin.tags[4]: невалидный формат "with space"`,
+	} {
+		if !hasError(r, want) {
+			t.Errorf("expected error containing %q, got: %v", want, r.Errors)
+		}
+	}
+	if !hasWarning(r, `plugin.tags[3]: тег "telegram" повторяется`) {
+		t.Errorf("expected duplicate warning, got: %v", r.Warnings)
+	}
+
+	spec.Plugin.Tags = strings.Fields("a b c d e f g h i j k")
+	if r := Validate(spec); !hasError(r, "plugin.tags: не более 10 тегов (получено 11)") {
+		t.Errorf("expected limit error, got: %v", r.Errors)
+	}
+}
@@ -0,0 +1,63 @@
+// Структурированное логирование клиента через log/slog.
+// Логгер опционален: при Client.Logger == nil SDK молчит.
+// Секреты (токены, Authorization, значения auth.env) в лог не попадают.
+package integrat
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// secretKeys — ключи атрибутов, значения которых всегда маскируются.
+var secretKeys = []string{"token", "authorization", "secret", "password", "api_key", "apikey"}
+
+// RedactAttr маскирует значения секретных атрибутов (token, authorization,
+// secret, password, api_key — по вхождению в имя ключа). Подходит как
+// slog.HandlerOptions.ReplaceAttr:
+//
+//	h := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{ReplaceAttr: integrat.RedactAttr})
+func RedactAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+	return a
+}
+
+// RedactToken возвращает токен в виде, пригодном для логов: префикс и
+// последние 4 символа (itg_…a1b2).
+func RedactToken(token string) string {
+	if len(token) <= 8 {
+		return redacted
+	}
+	prefix := ""
+	if i := strings.IndexByte(token, '_'); i > 0 && i < 8 {
+		prefix = token[:i+1]
+	}
+	return prefix + "…" + token[len(token)-4:]
+}
+
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.Logger == nil || !c.Logger.Enabled(ctx, level) {
+		return
+	}
+	for i, a := range attrs {
+		attrs[i] = RedactAttr(nil, a)
+	}
+	c.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// callAttrs — общие атрибуты лога для вызова.
+func callAttrs(call *CallInfo) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", call.Method),
+		slog.String("path", call.Path),
+	}
+	if call.Plugin != "" {
+		attrs = append(attrs, slog.String("plugin", call.Plugin), slog.String("endpoint", call.Endpoint))
+	}
+	return attrs
+}
@@ -0,0 +1,87 @@
+package integrat
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_RequestSummary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Integrat-Cached", "true")
+		w.Header().Set("X-Integrat-Stale", "true")
+		io.WriteString(w, `{"data":[1,2,3]}`)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	c := NewWithURL("itg_supersecret", srv.URL)
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	if _, err := c.Query("channel-mcp", "tags.top", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="integrat request"`,
+		"plugin=channel-mcp",
+		"endpoint=tags.top",
+		"level=WARN",
+		"cached=true",
+		"stale=true",
+		"bytes=16",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "supersecret") {
+		t.Errorf("log leaks token:\n%s", out)
+	}
+}
+
+func TestLogger_NilIsSilent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	if _, err := c.ListPlugins(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedactAttr(t *testing.T) {
+	tests := []struct {
+		key  string
+		keep bool
+	}{
+		{"token", false},
+		{"Authorization", false},
+		{"client_secret", false},
+		{"api_key", false},
+		{"plugin", true},
+		{"status", true},
+	}
+	for _, tt := range tests {
+		a := RedactAttr(nil, slog.String(tt.key, "value"))
+		if kept := a.Value.String() == "value"; kept != tt.keep {
+			t.Errorf("RedactAttr(%q) = %q", tt.key, a.Value.String())
+		}
+	}
+}
+
+func TestRedactToken(t *testing.T) {
+	if got := RedactToken("itg_abcdef123456"); got != "itg_…3456" {
+		t.Errorf("RedactToken = %q", got)
+	}
+	if got := RedactToken("short"); got != redacted {
+		t.Errorf("RedactToken(short) = %q", got)
+	}
+}
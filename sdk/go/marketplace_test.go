@@ -0,0 +1,98 @@
+package integrat
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSearchMarketplace_Filters(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/marketplace" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		got = r.URL.Query()
+		io.WriteString(w, `{"plugins":[{"id":3,"slug":"channel-mcp","tags":["telegram"],"rating":4.6,"rating_count":12,"health":"up"}],
+			"total":1,"page":1,"pages":1,
+			"facets":{"tags":{"telegram":1,"channels":1},"access_tiers":{"open":1,"gated":1},"health":{"up":1},
+				"prices":[{"min":0,"max":0,"count":0},{"min":1,"max":10,"count":1},{"min":11,"count":0}]}}`)
+	}))
+	defer srv.Close()
+
+	from, to := int64(1), int64(10)
+	res, err := NewWithURL("t", srv.URL).SearchMarketplace(MarketplaceSearchParams{
+		Query:      "telegram",
+		Tags:       []string{"telegram", "channels"},
+		AccessTier: "gated",
+		DataType:   "medium",
+		PriceMin:   &from,
+		PriceMax:   &to,
+		OwnerID:    7,
+		MinRating:  4.5,
+		Health:     HealthUp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "access_tier=gated&data_type=medium&health=up&min_rating=4.5&owner_id=7&price_max=10&price_min=1&q=telegram&tags=telegram%2Cchannels"
+	if got.Encode() != want {
+		t.Errorf("query = %s\nwant    %s", got.Encode(), want)
+	}
+	p := res.Plugins[0]
+	if p.Rating != 4.6 || p.RatingCount != 12 || p.Health != HealthUp || p.Tags[0] != "telegram" {
+		t.Errorf("plugin = %+v", p)
+	}
+	f := res.Facets
+	if f == nil || f.Tags["channels"] != 1 || f.AccessTiers["gated"] != 1 || len(f.Prices) != 3 {
+		t.Fatalf("facets = %+v", f)
+	}
+	if f.Prices[1].Count != 1 || *f.Prices[1].Max != 10 || f.Prices[2].Max != nil {
+		t.Errorf("prices = %+v", f.Prices)
+	}
+}
+
+func TestSearchMarketplace_FreeOnly(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RawQuery
+		io.WriteString(w, `{"plugins":[],"total":0,"page":1,"pages":0}`)
+	}))
+	defer srv.Close()
+
+	free := int64(0)
+	res, err := NewWithURL("t", srv.URL).SearchMarketplace(MarketplaceSearchParams{PriceMax: &free})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "price_max=0" || res.Facets != nil {
+		t.Errorf("query = %q, facets = %+v", got, res.Facets)
+	}
+}
+
+func TestSearchMarketplace_InvalidFilters(t *testing.T) {
+	c := NewWithURL("t", "http://127.0.0.1:0")
+	lo, hi, neg := int64(10), int64(5), int64(-1)
+	tests := []struct {
+		params MarketplaceSearchParams
+		want   string
+	}{
+		{MarketplaceSearchParams{Tags: []string{"a,b"}}, "tag"},
+		{MarketplaceSearchParams{AccessTier: "public"}, "access tier"},
+		{MarketplaceSearchParams{DataType: "huge"}, "data type"},
+		{MarketplaceSearchParams{Health: "ok"}, "health"},
+		{MarketplaceSearchParams{PriceMin: &lo, PriceMax: &hi}, "price_min 10 > price_max 5"},
+		{MarketplaceSearchParams{PriceMin: &neg}, ">= 0"},
+		{MarketplaceSearchParams{MinRating: 6}, "min_rating"},
+	}
+	for _, tt := range tests {
+		_, err := c.SearchMarketplace(tt.params)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: err = %v, want %q", tt.params, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Пакет metering — журнал вызовов плагинов и расчёт начислений: то, что
+// gateway делает при проксировании, для локального стенда и проверки цен
+// до публикации.
+//
+// Ledger записывает каждый вызов (плагин, эндпоинт, чат, вызывающий,
+// data_type, кеш, байты, план), Invoice агрегирует вызовы за календарный
+// месяц, применяет pricing из integrat.yaml (plans.included, free_tier,
+// per_call, endpoints, charge_cached) и комиссию платформы. Результат — integrat.Usage, тот же,
+// что возвращает Client.GetUsage; экспорт — WriteJSON и WriteCSV.
+//
+//	pricing, err := metering.LoadPricing(specYAML)
+//	ledger := metering.NewLedger()
+//	ledger.Record(metering.Call{Time: time.Now(), Plugin: "channel-mcp", Endpoint: "messages.search", ...})
+//	usage, err := ledger.Invoice("channel-mcp", "2026-10", pricing)
+//	metering.WriteCSV(os.Stdout, usage)
+package metering
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+	"github.com/plagness/Integrat/sdk/go/internal/validator"
+)
+
+// Call — один проксированный вызов.
+type Call struct {
+	Time     time.Time
+	Plugin   string
+	Endpoint string
+	ChatID   int64  // 0 — вызов вне чата
+	Caller   string // пользователь или токен; к нему применяются free_tier и included плана
+	Plan     string // slug плана, действовавшего у Caller; "" — оплата за вызов
+	DataType string
+	Cached   bool
+	Bytes    int64
+}
+
+// ── Pricing ─────────────────────────────────────────────────────────────
+
+// Pricing — цены за вызов и тарифные планы (секция pricing integrat.yaml).
+type Pricing struct {
+	FreeTier     *FreeTier
+	Plans        []integrat.Plan  // included покрывает вызовы с Call.Plan
+	PerCall      map[string]int64 // по data_type
+	Endpoints    map[string]int64 // по slug эндпоинта; важнее PerCall
+	ChargeCached bool
+}
+
+// FreeTier — бесплатные вызовы на Caller за период.
+type FreeTier struct {
+	Requests int64
+	Period   string // day, week, month (по умолчанию)
+}
+
+// LoadPricing читает pricing из integrat.yaml. Спецификация проверяется
+// валидатором; без секции pricing все вызовы бесплатны.
+func LoadPricing(specYAML []byte) (*Pricing, error) {
+	spec, res := validator.ValidateBytes(specYAML)
+	if !res.OK() {
+		return nil, fmt.Errorf("metering: invalid spec: %s", strings.Join(res.Errors, "; "))
+	}
+	p := &Pricing{}
+	if spec.Pricing == nil {
+		return p, nil
+	}
+	for _, pl := range spec.Pricing.Plans {
+		period := pl.Period
+		if period == "" {
+			period = integrat.PlanPeriodMonth
+		}
+		p.Plans = append(p.Plans, integrat.Plan{Slug: pl.Slug, Name: pl.Name, Description: pl.Description,
+			Price: int64(pl.Price), Period: period, Included: int64(pl.Included), Endpoints: pl.Endpoints})
+	}
+	p.ChargeCached = spec.Pricing.ChargeCached
+	if ft := spec.Pricing.FreeTier; ft != nil {
+		p.FreeTier = &FreeTier{Requests: int64(ft.Requests), Period: ft.Period}
+	}
+	p.PerCall = make(map[string]int64, len(spec.Pricing.PerCall))
+	for k, v := range spec.Pricing.PerCall {
+		p.PerCall[k] = int64(v)
+	}
+	p.Endpoints = make(map[string]int64, len(spec.Pricing.Endpoints))
+	for k, v := range spec.Pricing.Endpoints {
+		p.Endpoints[k] = int64(v)
+	}
+	return p, nil
+}
+
+// Price возвращает цену вызова эндпоинта.
+func (p *Pricing) Price(endpoint, dataType string) int64 {
+	if p == nil {
+		return 0
+	}
+	if price, ok := p.Endpoints[endpoint]; ok {
+		return price
+	}
+	return p.PerCall[dataType]
+}
+
+// plan возвращает план по slug.
+func (p *Pricing) plan(slug string) *integrat.Plan {
+	for i := range p.Plans {
+		if p.Plans[i].Slug == slug {
+			return &p.Plans[i]
+		}
+	}
+	return nil
+}
+
+// planCovers сообщает, входит ли эндпоинт в план.
+func planCovers(pl *integrat.Plan, endpoint string) bool {
+	if len(pl.Endpoints) == 0 {
+		return true
+	}
+	for _, e := range pl.Endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// planBucket — начало периода included плана, в который попадает t:
+// календарный месяц или год UTC; для разовой покупки — без границы.
+func planBucket(pl *integrat.Plan, t time.Time) time.Time {
+	t = t.UTC()
+	switch pl.Period {
+	case integrat.PlanPeriodYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case integrat.PlanPeriodOnce:
+		return time.Time{}
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// freeBucket — начало периода free_tier, в который попадает t.
+func (ft *FreeTier) freeBucket(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch ft.Period {
+	case "day":
+		return day
+	case "week":
+		// Неделя с понедельника.
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ── Ledger ──────────────────────────────────────────────────────────────
+
+// Ledger — журнал вызовов в памяти. Безопасен для конкурентного использования.
+type Ledger struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewLedger создаёт пустой журнал.
+func NewLedger() *Ledger {
+	return &Ledger{}
+}
+
+// Record добавляет вызов в журнал.
+func (l *Ledger) Record(c Call) {
+	l.mu.Lock()
+	l.calls = append(l.calls, c)
+	l.mu.Unlock()
+}
+
+// Calls возвращает вызовы плагина в интервале [from, to) по времени.
+func (l *Ledger) Calls(plugin string, from, to time.Time) []Call {
+	l.mu.Lock()
+	var out []Call
+	for _, c := range l.calls {
+		if c.Plugin == plugin && !c.Time.Before(from) && c.Time.Before(to) {
+			out = append(out, c)
+		}
+	}
+	l.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// ParsePeriod возвращает границы расчётного периода "YYYY-MM" (UTC).
+func ParsePeriod(period string) (from, to time.Time, err error) {
+	from, err = time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("metering: invalid period %q (want YYYY-MM)", period)
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// Invoice считает использование плагина за период "YYYY-MM". Вызов с
+// Call.Plan сначала расходует included плана (если план покрывает
+// эндпоинт), затем free tier, сверх них оплачивается по цене вызова.
+// Квоты расходуются по порядку вызовов каждого Caller за весь свой
+// период: неделя free_tier на стыке месяцев и годовой план учитывают и
+// вызовы до from. Вызовы из кеша без ChargeCached бесплатны и квоты не
+// расходуют. Стоимость подписок в счёт не входит — её списывает
+// маркетплейс при оформлении (см. integrat.Client.Subscribe).
+func (l *Ledger) Invoice(plugin, period string, pricing *Pricing) (*integrat.Usage, error) {
+	from, to, err := ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &integrat.Usage{Plugin: plugin, Period: period, From: from, To: to, Lines: []integrat.UsageLine{}}
+	lines := make(map[string]*integrat.UsageLine)
+	freeUsed := make(map[string]int64) // caller + начало периода free_tier
+	planUsed := make(map[string]int64) // caller + план + начало периода
+
+	// Вызовы до from только расходуют квоты периодов, начатых раньше.
+	start := from
+	if pricing != nil {
+		if pricing.FreeTier != nil {
+			start = pricing.FreeTier.freeBucket(from)
+		}
+		for i := range pricing.Plans {
+			if b := planBucket(&pricing.Plans[i], from); b.Before(start) {
+				start = b
+			}
+		}
+	}
+
+	for _, c := range l.Calls(plugin, start, to) {
+		var pl *integrat.Plan
+		if c.Plan != "" && pricing != nil {
+			if pl = pricing.plan(c.Plan); pl == nil {
+				return nil, fmt.Errorf("metering: call at %s references unknown plan %q", c.Time.Format(time.RFC3339), c.Plan)
+			}
+		}
+		price := pricing.Price(c.Endpoint, c.DataType)
+		charged := price != 0 && (!c.Cached || pricing.ChargeCached)
+		planned, free := false, false
+		if charged && pl != nil && planCovers(pl, c.Endpoint) {
+			key := c.Caller + "\x00" + pl.Slug + "\x00" + planBucket(pl, c.Time).Format(time.RFC3339)
+			if pl.Included == 0 || planUsed[key] < pl.Included {
+				planUsed[key]++
+				planned = true
+			}
+		}
+		if charged && !planned && pricing.FreeTier != nil {
+			ft := pricing.FreeTier
+			key := c.Caller + "\x00" + ft.freeBucket(c.Time).Format(time.RFC3339)
+			if freeUsed[key] < ft.Requests {
+				freeUsed[key]++
+				free = true
+			}
+		}
+		if c.Time.Before(from) {
+			continue
+		}
+
+		line, ok := lines[c.Endpoint]
+		if !ok {
+			line = &integrat.UsageLine{Endpoint: c.Endpoint, DataType: c.DataType}
+			lines[c.Endpoint] = line
+		}
+		line.UnitPrice = price
+		line.Calls++
+		line.Bytes += c.Bytes
+		u.Calls++
+		if c.Cached {
+			line.CachedCalls++
+		}
+		switch {
+		case planned:
+			line.PlanCalls++
+		case free:
+			line.FreeCalls++
+		case charged:
+			line.BillableCalls++
+			line.Amount += price
+		}
+	}
+
+	for _, line := range lines {
+		u.Lines = append(u.Lines, *line)
+		u.Gross += line.Amount
+	}
+	sort.Slice(u.Lines, func(i, j int) bool { return u.Lines[i].Endpoint < u.Lines[j].Endpoint })
+	u.Commission = integrat.Commission(u.Gross)
+	u.Net = u.Gross - u.Commission
+	return u, nil
+}
+
+// ── Экспорт ─────────────────────────────────────────────────────────────
+
+// WriteJSON пишет отчёт в JSON (формат ответа GET /v1/plugins/:id/usage).
+func WriteJSON(w io.Writer, u *integrat.Usage) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(u)
+}
+
+// WriteCSV пишет отчёт в CSV: строка на эндпоинт, затем total,
+// commission и net (суммы — в колонке amount; plan_calls — последняя).
+func WriteCSV(w io.Writer, u *integrat.Usage) error {
+	if u == nil {
+		return errors.New("metering: nil usage")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"period", "endpoint", "data_type", "calls", "cached_calls", "free_calls", "billable_calls", "bytes", "unit_price", "amount", "plan_calls"})
+
+	var cached, free, billable, bytes, planned int64
+	for _, l := range u.Lines {
+		cw.Write([]string{u.Period, l.Endpoint, l.DataType, itoa(l.Calls), itoa(l.CachedCalls), itoa(l.FreeCalls),
+			itoa(l.BillableCalls), itoa(l.Bytes), itoa(l.UnitPrice), itoa(l.Amount), itoa(l.PlanCalls)})
+		cached += l.CachedCalls
+		free += l.FreeCalls
+		billable += l.BillableCalls
+		bytes += l.Bytes
+		planned += l.PlanCalls
+	}
+	cw.Write([]string{u.Period, "total", "", itoa(u.Calls), itoa(cached), itoa(free), itoa(billable), itoa(bytes), "", itoa(u.Gross), itoa(planned)})
+	cw.Write([]string{u.Period, "commission", "", "", "", "", "", "", "", itoa(u.Commission), ""})
+	cw.Write([]string{u.Period, "net", "", "", "", "", "", "", "", itoa(u.Net), ""})
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func itoa(n int64) string { return strconv.FormatInt(n, 10) }
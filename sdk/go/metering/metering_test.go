@@ -0,0 +1,246 @@
+package metering
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+const specYAML = `
+plugin:
+  slug: channel-mcp
+  name: Channel
+  description: D
+  version: "1"
+provider:
+  base_url: http://x
+endpoints:
+  - slug: messages.fetch
+    name: Fetch
+    path: /fetch
+    access: open
+    data_type: basic
+  - slug: messages.search
+    name: Search
+    path: /search
+    access: open
+    data_type: complex
+pricing:
+  free_tier:
+    requests: 2
+    period: day
+  per_call:
+    basic: 1
+    complex: 5
+  endpoints:
+    messages.search: 10
+`
+
+const planYAML = `  plans:
+    - slug: pro
+      name: Pro
+      price: 100
+      included: 2
+      endpoints: [messages.search]
+`
+
+func mustPricing(t *testing.T) *Pricing {
+	t.Helper()
+	p, err := LoadPricing([]byte(specYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadPricing(t *testing.T) {
+	p := mustPricing(t)
+	if p.Price("messages.search", "complex") != 10 || p.Price("messages.fetch", "basic") != 1 {
+		t.Errorf("pricing = %+v", p)
+	}
+	if p.FreeTier == nil || p.FreeTier.Requests != 2 || p.FreeTier.Period != "day" {
+		t.Errorf("free tier = %+v", p.FreeTier)
+	}
+
+	if _, err := LoadPricing([]byte("plugin: {}")); err == nil {
+		t.Error("expected error for invalid spec")
+	}
+
+	p, err := LoadPricing([]byte(specYAML + planYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := integrat.Plan{Slug: "pro", Name: "Pro", Price: 100, Period: integrat.PlanPeriodMonth, Included: 2, Endpoints: []string{"messages.search"}}
+	if len(p.Plans) != 1 || p.Plans[0].Slug != want.Slug || p.Plans[0].Period != want.Period ||
+		p.Plans[0].Included != want.Included || len(p.Plans[0].Endpoints) != 1 {
+		t.Errorf("plans = %+v", p.Plans)
+	}
+}
+
+func TestInvoice_PlanIncluded(t *testing.T) {
+	p, err := LoadPricing([]byte(specYAML + planYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLedger()
+	day := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	call := func(at time.Time, endpoint, dataType, plan string) {
+		l.Record(Call{Time: at, Plugin: "channel-mcp", Endpoint: endpoint, DataType: dataType, Caller: "alice", Plan: plan})
+	}
+	// Сентябрьские вызовы не расходуют included октября.
+	call(day.AddDate(0, -1, 0), "messages.search", "complex", "pro")
+	// 2 поиска по плану, затем 2 из free tier, затем оплата за вызов.
+	for i := 0; i < 5; i++ {
+		call(day.Add(time.Duration(i)*time.Minute), "messages.search", "complex", "pro")
+	}
+	// fetch не входит в план — расходует только free tier (уже пуст).
+	call(day.Add(time.Hour), "messages.fetch", "basic", "pro")
+
+	u, err := l.Invoice("channel-mcp", "2026-10", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []integrat.UsageLine{
+		{Endpoint: "messages.fetch", DataType: "basic", Calls: 1, BillableCalls: 1, UnitPrice: 1, Amount: 1},
+		{Endpoint: "messages.search", DataType: "complex", Calls: 5, PlanCalls: 2, FreeCalls: 2, BillableCalls: 1, UnitPrice: 10, Amount: 10},
+	}
+	if len(u.Lines) != len(want) {
+		t.Fatalf("lines = %+v", u.Lines)
+	}
+	for i := range want {
+		if u.Lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, u.Lines[i], want[i])
+		}
+	}
+
+	call(day, "messages.search", "complex", "gold")
+	if _, err := l.Invoice("channel-mcp", "2026-10", p); err == nil || !strings.Contains(err.Error(), `"gold"`) {
+		t.Errorf("unknown plan: err = %v", err)
+	}
+}
+
+func TestInvoice(t *testing.T) {
+	l := NewLedger()
+	day := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	call := func(at time.Time, endpoint, dataType, caller string, cached bool) {
+		l.Record(Call{Time: at, Plugin: "channel-mcp", Endpoint: endpoint, DataType: dataType,
+			Caller: caller, ChatID: 42, Cached: cached, Bytes: 100})
+	}
+	// alice: 2 бесплатных, затем 2 платных поиска; кеш не списывается.
+	call(day, "messages.search", "complex", "alice", false)
+	call(day.Add(time.Minute), "messages.fetch", "basic", "alice", false)
+	call(day.Add(2*time.Minute), "messages.search", "complex", "alice", false)
+	call(day.Add(3*time.Minute), "messages.search", "complex", "alice", false)
+	call(day.Add(4*time.Minute), "messages.search", "complex", "alice", true)
+	// На следующий день free tier снова доступен.
+	call(day.Add(24*time.Hour), "messages.fetch", "basic", "alice", false)
+	// Другой пользователь, другой плагин, другой месяц — не влияют.
+	call(day, "messages.fetch", "basic", "bob", false)
+	l.Record(Call{Time: day, Plugin: "other", Endpoint: "x", Caller: "alice"})
+	call(day.AddDate(0, 1, 0), "messages.search", "complex", "alice", false)
+
+	u, err := l.Invoice("channel-mcp", "2026-10", mustPricing(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []integrat.UsageLine{
+		{Endpoint: "messages.fetch", DataType: "basic", Calls: 3, FreeCalls: 3, Bytes: 300, UnitPrice: 1},
+		{Endpoint: "messages.search", DataType: "complex", Calls: 4, CachedCalls: 1, FreeCalls: 1, BillableCalls: 2, Bytes: 400, UnitPrice: 10, Amount: 20},
+	}
+	if len(u.Lines) != len(want) {
+		t.Fatalf("lines = %+v", u.Lines)
+	}
+	for i := range want {
+		if u.Lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, u.Lines[i], want[i])
+		}
+	}
+	if u.Calls != 7 || u.Gross != 20 || u.Commission != 0 || u.Net != 20 {
+		t.Errorf("totals: calls %d, gross %d, commission %d, net %d", u.Calls, u.Gross, u.Commission, u.Net)
+	}
+	if !u.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !u.To.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("period = %s..%s", u.From, u.To)
+	}
+}
+
+func TestInvoice_FreeTierWeekAcrossMonths(t *testing.T) {
+	l := NewLedger()
+	// Неделя с понедельника 28.09.2026 по 04.10.2026.
+	for _, at := range []time.Time{
+		time.Date(2026, 9, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), // новая неделя
+		time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC),
+	} {
+		l.Record(Call{Time: at, Plugin: "p", Endpoint: "e", Caller: "u"})
+	}
+	p := &Pricing{Endpoints: map[string]int64{"e": 10}, FreeTier: &FreeTier{Requests: 2, Period: "week"}}
+
+	u, err := l.Invoice("p", "2026-10", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Сентябрьские вызовы уже израсходовали free tier недели.
+	want := integrat.UsageLine{Endpoint: "e", Calls: 3, FreeCalls: 2, BillableCalls: 1, UnitPrice: 10, Amount: 10}
+	if len(u.Lines) != 1 || u.Lines[0] != want || u.Calls != 3 {
+		t.Errorf("lines = %+v, calls %d; want %+v", u.Lines, u.Calls, want)
+	}
+}
+
+func TestInvoice_CommissionAndChargeCached(t *testing.T) {
+	l := NewLedger()
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		l.Record(Call{Time: at, Plugin: "p", Endpoint: "e", Caller: "u", Cached: i%2 == 0})
+	}
+	p := &Pricing{Endpoints: map[string]int64{"e": 10}, ChargeCached: true}
+	u, err := l.Invoice("p", "2026-10", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Gross != 1000 || u.Commission != 10 || u.Net != 990 {
+		t.Errorf("gross %d, commission %d, net %d", u.Gross, u.Commission, u.Net)
+	}
+
+	if u, _ := l.Invoice("p", "2026-10", nil); u.Gross != 0 || u.Calls != 100 {
+		t.Errorf("nil pricing: %+v", u)
+	}
+	if _, err := l.Invoice("p", "10/2026", p); err == nil {
+		t.Error("expected error for invalid period")
+	}
+}
+
+func TestExport(t *testing.T) {
+	u := &integrat.Usage{
+		Plugin: "p", Period: "2026-10", Calls: 3, Gross: 100, Commission: 1, Net: 99,
+		Lines: []integrat.UsageLine{{Endpoint: "e", DataType: "basic", Calls: 3, BillableCalls: 2, Bytes: 10, UnitPrice: 50, Amount: 100}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, u); err != nil {
+		t.Fatal(err)
+	}
+	var back integrat.Usage
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil || back.Net != 99 || back.Lines[0].Amount != 100 {
+		t.Errorf("json round trip: %+v, %v", back, err)
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, u); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || rows[1][1] != "e" || rows[1][9] != "100" || rows[3][1] != "commission" || rows[4][9] != "99" || rows[0][10] != "plan_calls" {
+		t.Errorf("csv = %v", rows)
+	}
+}
@@ -0,0 +1,158 @@
+// Цепочка HTTP-middleware клиента.
+// Middleware оборачивает отправку запроса и может менять запрос/ответ,
+// логировать, добавлять заголовки и т.п.
+package integrat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"time"
+)
+
+// RoundTripFunc отправляет запрос и возвращает ответ.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware оборачивает следующий RoundTripFunc в цепочке.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use добавляет middleware в цепочку. Первый добавленный — самый внешний:
+// видит запрос первым и ответ последним. Безопасен для вызова
+// параллельно с запросами: уже начатые запросы идут по прежней цепочке.
+func (c *Client) Use(mw ...Middleware) {
+	c.mwMu.Lock()
+	defer c.mwMu.Unlock()
+	chain := make([]Middleware, 0, len(c.middleware)+len(mw))
+	c.middleware = append(append(chain, c.middleware...), mw...)
+}
+
+// do отправляет запрос через цепочку middleware и HTTPClient. Потоковые
+// вызовы (call.Stream) идут без HTTPClient.Timeout: он ограничивает и
+// чтение тела, а поток живёт дольше — его срок задаёт ctx запроса.
+func (c *Client) do(req *http.Request, call *CallInfo) (*http.Response, error) {
+	c.mwMu.Lock()
+	chain := c.middleware
+	c.mwMu.Unlock()
+
+	hc := c.HTTPClient
+	if call != nil && call.Stream && hc.Timeout != 0 {
+		nc := *hc
+		nc.Timeout = 0
+		hc = &nc
+	}
+	next := RoundTripFunc(hc.Do)
+	for i := len(chain) - 1; i >= 0; i-- {
+		next = chain[i](next)
+	}
+	return next(req)
+}
+
+// ── Встроенные middleware ───────────────────────────────────────────────
+
+// RequestIDHeader — заголовок с идентификатором запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// redacted — замена секретных значений в логах и дампах.
+const redacted = "[REDACTED]"
+
+// RequestID проставляет X-Request-ID, если он ещё не задан.
+func RequestID() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIDHeader) == "" {
+				req.Header.Set(RequestIDHeader, newRequestID())
+			}
+			return next(req)
+		}
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Logging пишет в logger строку на каждый запрос: метод, путь, статус,
+// длительность и X-Request-ID. Ошибки транспорта — уровень Error,
+// ответы со статусом >= 400 — Warn, остальные — Info.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if id := req.Header.Get(RequestIDHeader); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+
+			ctx := req.Context()
+			switch {
+			case err != nil:
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "integrat request failed", attrs...)
+			case resp.StatusCode >= 400:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				logger.LogAttrs(ctx, slog.LevelWarn, "integrat request", attrs...)
+			default:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				logger.LogAttrs(ctx, slog.LevelInfo, "integrat request", attrs...)
+			}
+			return resp, err
+		}
+	}
+}
+
+// Headers добавляет заголовки к каждому запросу (заменяя одноимённые).
+func Headers(h http.Header) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			for k, vs := range h {
+				req.Header.Del(k)
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			return next(req)
+		}
+	}
+}
+
+// Dump пишет в w полные запрос и ответ в wire-формате (для отладки).
+// Заголовок Authorization заменяется на [REDACTED].
+// Тело ответа буферизуется целиком — не используйте с потоковыми запросами.
+func Dump(w io.Writer) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			clone := req.Clone(req.Context())
+			if clone.Header.Get("Authorization") != "" {
+				clone.Header.Set("Authorization", redacted)
+			}
+			if dump, err := httputil.DumpRequestOut(clone, true); err == nil {
+				// DumpRequestOut вычитал тело и подменил его копией
+				req.Body = clone.Body
+				fmt.Fprintf(w, "%s\n", dump)
+			}
+
+			resp, err := next(req)
+			if err != nil {
+				return resp, err
+			}
+			if dump, err := httputil.DumpResponse(resp, true); err == nil {
+				fmt.Fprintf(w, "%s\n", dump)
+			}
+			return resp, nil
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package integrat
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUse_Order(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	var calls []string
+	mark := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+">")
+				resp, err := next(req)
+				calls = append(calls, "<"+name)
+				return resp, err
+			}
+		}
+	}
+
+	c := NewWithURL("t", srv.URL)
+	c.Use(mark("a"), mark("b"))
+	c.Use(mark("c"))
+	if _, err := c.ListPlugins(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a> b> c> <c <b <a"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestUse_Concurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Use(Headers(http.Header{"X-Test": {"1"}}))
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListPlugins(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRequestIDAndHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	c.Use(RequestID(), Headers(http.Header{"X-Tenant": {"acme"}}))
+	if err := c.Health(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Get(RequestIDHeader)) != 32 {
+		t.Errorf("X-Request-ID = %q, want 32 hex chars", got.Get(RequestIDHeader))
+	}
+	if got.Get("X-Tenant") != "acme" {
+		t.Errorf("X-Tenant = %q", got.Get("X-Tenant"))
+	}
+}
+
+func TestDump_RedactsToken(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer itg_secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		io.WriteString(w, `{"id":1,"slug":"x"}`)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	c := NewWithURL("itg_secret", srv.URL)
+	c.Use(Dump(&buf))
+	p, err := c.CreatePlugin(CreatePluginParams{Name: "X", Slug: "x", BaseURL: "http://x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Slug != "x" {
+		t.Errorf("response body lost after dump: %+v", p)
+	}
+	if body["slug"] != "x" {
+		t.Errorf("request body lost after dump: %v", body)
+	}
+	out := buf.String()
+	if strings.Contains(out, "itg_secret") {
+		t.Errorf("dump leaks token:\n%s", out)
+	}
+	if !strings.Contains(out, "Authorization: [REDACTED]") || !strings.Contains(out, `"slug":"x"`) {
+		t.Errorf("unexpected dump:\n%s", out)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	c := NewWithURL("itg_secret", srv.URL)
+	c.Use(RequestID(), Logging(slog.New(slog.NewTextHandler(&buf, nil))))
+	c.GetPlugin(7)
+
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "path=/v1/plugins/7", "status=404", "request_id="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "itg_secret") {
+		t.Errorf("log leaks token:\n%s", out)
+	}
+}
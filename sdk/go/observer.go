@@ -0,0 +1,79 @@
+// Observer — хуки начала и конца каждого вызова API для трейсинга и метрик.
+// Готовый адаптер со спанами, гистограммами и W3C traceparent — пакет telemetry.
+package integrat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// CallInfo описывает вызов API.
+type CallInfo struct {
+	Method   string      // HTTP-метод
+	Path     string      // путь API, например /v1/query
+	Plugin   string      // slug плагина (только для запросов данных)
+	Endpoint string      // slug эндпоинта (только для запросов данных)
+	Stream   bool        // потоковый запрос (OpenStream/QueryStream)
+	Header   http.Header // заголовки исходящего запроса — можно дополнять в CallStart
+}
+
+// CallResult — итог вызова API.
+type CallResult struct {
+	StatusCode int           // HTTP-статус (0 — ответ не получен)
+	Cached     bool          // X-Integrat-Cached
+	Stale      bool          // X-Integrat-Stale
+	Bytes      int64         // размер прочитанного тела ответа
+	Duration   time.Duration // от отправки до прочтения ответа
+	Err        error         // ошибка вызова
+	Sentinel   error         // sentinel-ошибка (ErrNotFound, ErrProvider, ...) или context-ошибка
+}
+
+// Observer получает события по каждому вызову API.
+//
+// CallStart вызывается перед отправкой; возвращённый контекст становится
+// контекстом запроса и передаётся в CallEnd. Через call.Header можно
+// пробросить заголовки (например, traceparent). Для потоковых запросов
+// CallEnd вызывается при закрытии Stream.
+type Observer interface {
+	CallStart(ctx context.Context, call *CallInfo) context.Context
+	CallEnd(ctx context.Context, call *CallInfo, res *CallResult)
+}
+
+// observe оповещает Observer о начале вызова и возвращает запрос с новым
+// контекстом и функцию завершения.
+func (c *Client) observe(req *http.Request, call *CallInfo) (*http.Request, func(*CallResult)) {
+	if c.Observer == nil {
+		return req, func(*CallResult) {}
+	}
+
+	call.Header = req.Header
+	start := time.Now()
+	ctx := c.Observer.CallStart(req.Context(), call)
+	return req.WithContext(ctx), func(res *CallResult) {
+		res.Duration = time.Since(start)
+		if res.Sentinel == nil {
+			res.Sentinel = sentinelOf(res.Err)
+		}
+		c.Observer.CallEnd(ctx, call, res)
+	}
+}
+
+// sentinelOf возвращает sentinel-ошибку, стоящую за err.
+func sentinelOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.Err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return context.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return context.DeadlineExceeded
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Конструктор клиента с функциональными опциями.
+package integrat
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Переменные окружения, из которых NewClient берёт значения по умолчанию.
+const (
+	EnvToken   = "INTEGRAT_TOKEN"
+	EnvBaseURL = "INTEGRAT_BASE_URL"
+)
+
+// Option настраивает клиент в NewClient.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	baseURL     string
+	token       string
+	tokenSource TokenSource
+	credentials *Credentials
+	httpClient  *http.Client
+	timeout     time.Duration
+	userAgent   string
+	retry       RetryPolicy
+	cache       Cache
+	cacheScope  string
+	logger      *slog.Logger
+	chatID      int64
+	observer    Observer
+	middleware  []Middleware
+	params      bool
+	strict      bool
+	schemaTTL   time.Duration
+}
+
+// NewClient создаёт клиент с опциями. Токен и базовый URL по умолчанию
+// берутся из INTEGRAT_TOKEN и INTEGRAT_BASE_URL, опции их переопределяют.
+//
+//	client := integrat.NewClient(
+//	    integrat.WithTimeout(10*time.Second),
+//	    integrat.WithRetryPolicy(integrat.DefaultRetryPolicy),
+//	    integrat.WithCache(integrat.NewMemoryCache(500)),
+//	)
+func NewClient(opts ...Option) *Client {
+	o := clientOptions{
+		baseURL:   DefaultBaseURL,
+		token:     os.Getenv(EnvToken),
+		userAgent: DefaultUserAgent,
+	}
+	if u := os.Getenv(EnvBaseURL); u != "" {
+		o.baseURL = u
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		timeout := o.timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		httpClient = &http.Client{Timeout: timeout}
+	} else if o.timeout > 0 && o.timeout != httpClient.Timeout {
+		// Не меняем переданный клиент — он может использоваться где-то ещё.
+		cp := *httpClient
+		cp.Timeout = o.timeout
+		httpClient = &cp
+	}
+
+	if o.tokenSource == nil && o.credentials != nil {
+		ex := NewExchangeToken(o.baseURL, *o.credentials)
+		ex.HTTPClient = httpClient
+		o.tokenSource = ex
+	}
+
+	return &Client{
+		BaseURL:       o.baseURL,
+		Token:         o.token,
+		TokenSource:   o.tokenSource,
+		HTTPClient:    httpClient,
+		UserAgent:     o.userAgent,
+		Retry:         o.retry,
+		Cache:         o.cache,
+		CacheScope:    o.cacheScope,
+		DefaultChatID: o.chatID,
+		Observer:      o.observer,
+		Logger:        o.logger,
+		middleware:    o.middleware,
+
+		ValidateParams:  o.params,
+		StrictResponses: o.strict,
+		SchemaTTL:       o.schemaTTL,
+	}
+}
+
+// WithBaseURL задаёт базовый URL API (self-hosted, dev).
+func WithBaseURL(u string) Option {
+	return func(o *clientOptions) { o.baseURL = u }
+}
+
+// WithToken задаёт статический API-токен.
+func WithToken(token string) Option {
+	return func(o *clientOptions) { o.token = token }
+}
+
+// WithTokenSource задаёт источник токена (приоритетнее WithToken).
+func WithTokenSource(ts TokenSource) Option {
+	return func(o *clientOptions) { o.tokenSource = ts }
+}
+
+// WithCredentials получает токен обменом учётных данных на /v1/auth/token
+// и обновляет его по истечении и после 401 (см. ExchangeToken).
+func WithCredentials(creds Credentials) Option {
+	return func(o *clientOptions) { o.credentials = &creds }
+}
+
+// WithHTTPClient задаёт HTTP-клиент. Таймаут берётся из него,
+// если не указан WithTimeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = hc }
+}
+
+// WithTimeout задаёт таймаут HTTP-запроса (по умолчанию 30s). На потоки
+// (OpenStream, QueryStream, SubscribeUpdates) он не действует — их
+// ограничивает ctx.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = d }
+}
+
+// WithUserAgent задаёт заголовок User-Agent.
+func WithUserAgent(ua string) Option {
+	return func(o *clientOptions) { o.userAgent = ua }
+}
+
+// WithRetryPolicy включает повтор временных ошибок.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *clientOptions) { o.retry = p }
+}
+
+// WithCache включает клиентский кеш ответов Query.
+func WithCache(c Cache) Option {
+	return func(o *clientOptions) { o.cache = c }
+}
+
+// WithCacheScope задаёт область записей в общем Cache (например, ID
+// пользователя). По умолчанию область — хеш токена.
+func WithCacheScope(scope string) Option {
+	return func(o *clientOptions) { o.cacheScope = scope }
+}
+
+// WithLogger задаёт slog-логгер для диагностики.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *clientOptions) { o.logger = l }
+}
+
+// WithDefaultChatID задаёт chat_id для запросов без явного чата.
+func WithDefaultChatID(chatID int64) Option {
+	return func(o *clientOptions) { o.chatID = chatID }
+}
+
+// WithObserver задаёт Observer для трейсинга и метрик.
+func WithObserver(obs Observer) Option {
+	return func(o *clientOptions) { o.observer = obs }
+}
+
+// WithMiddleware добавляет middleware (как Client.Use).
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *clientOptions) { o.middleware = append(o.middleware, mw...) }
+}
+
+// WithParamsValidation включает локальную проверку параметров Query по
+// params_schema эндпоинта (Client.ValidateParams).
+func WithParamsValidation() Option {
+	return func(o *clientOptions) { o.params = true }
+}
+
+// WithStrictResponses включает проверку ответов Query по response_schema
+// эндпоинта (Client.StrictResponses).
+func WithStrictResponses() Option {
+	return func(o *clientOptions) { o.strict = true }
+}
+
+// WithSchemaTTL задаёт срок кеша описаний эндпоинтов (Client.SchemaTTL).
+func WithSchemaTTL(d time.Duration) Option {
+	return func(o *clientOptions) { o.schemaTTL = d }
+}
@@ -0,0 +1,293 @@
+package integrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClient_EnvDefaults(t *testing.T) {
+	t.Setenv(EnvToken, "itg_env")
+	t.Setenv(EnvBaseURL, "http://env.local")
+
+	c := NewClient()
+	if c.Token != "itg_env" || c.BaseURL != "http://env.local" {
+		t.Errorf("env defaults not applied: token=%q base=%q", c.Token, c.BaseURL)
+	}
+	if c.HTTPClient.Timeout != 30*time.Second || c.UserAgent != DefaultUserAgent {
+		t.Errorf("defaults: timeout=%v ua=%q", c.HTTPClient.Timeout, c.UserAgent)
+	}
+
+	c = NewClient(WithToken("itg_opt"), WithBaseURL("http://opt.local"))
+	if c.Token != "itg_opt" || c.BaseURL != "http://opt.local" {
+		t.Errorf("options must override env: token=%q base=%q", c.Token, c.BaseURL)
+	}
+}
+
+func TestNewClient_TimeoutDoesNotMutateHTTPClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+
+	c := NewClient(WithTimeout(5*time.Second), WithHTTPClient(hc))
+	if c.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", c.HTTPClient.Timeout)
+	}
+	if hc.Timeout != time.Minute {
+		t.Error("caller's http.Client was mutated")
+	}
+
+	c = NewClient(WithHTTPClient(hc))
+	if c.HTTPClient != hc {
+		t.Error("http.Client without WithTimeout must be used as is")
+	}
+}
+
+func TestNewClient_HeadersAndDefaultChat(t *testing.T) {
+	var auth, ua string
+	var qr QueryRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ua = r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+		json.NewDecoder(r.Body).Decode(&qr)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(
+		WithBaseURL(srv.URL),
+		WithTokenSource(StaticToken("itg_src")),
+		WithUserAgent("my-bot/1.0"),
+		WithDefaultChatID(-100500),
+	)
+	if _, err := c.Query("p", "e", nil); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer itg_src" || ua != "my-bot/1.0" || qr.ChatID != -100500 {
+		t.Errorf("auth=%q ua=%q chat_id=%d", auth, ua, qr.ChatID)
+	}
+
+	if _, err := c.QueryInChat("p", "e", 42, nil); err != nil {
+		t.Fatal(err)
+	}
+	if qr.ChatID != 42 {
+		t.Errorf("explicit chat_id = %d, want 42", qr.ChatID)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			t.Error("retried request has empty body")
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  2 * time.Millisecond,
+	}))
+	if _, err := c.Query("p", "e", map[string]any{"x": 1}); err != nil {
+		t.Fatalf("Query after retries: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestRetryPolicy_NonIdempotentNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	if _, err := c.CreatePlugin(CreatePluginParams{Name: "x"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("POST /v1/plugins retried: calls = %d", calls.Load())
+	}
+}
+
+func TestRetryPolicy_QueryNotRetriedAfterProvider(t *testing.T) {
+	for _, status := range []int{http.StatusBadGateway, http.StatusGatewayTimeout} {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(status)
+		}))
+
+		c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+		if _, err := c.Query("p", "e", nil); !errors.Is(err, ErrProvider) {
+			t.Errorf("%d: err = %v", status, err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("%d: query retried (calls = %d) — may bill twice", status, calls.Load())
+		}
+		if _, err := c.ListPlugins(); err == nil || calls.Load() != 4 {
+			t.Errorf("%d: GET must still be retried: calls = %d", status, calls.Load())
+		}
+		srv.Close()
+	}
+}
+
+func TestRetryPolicy_QueryRetriedWhenNotSent(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close() // соединение будет отклонено
+
+	var attempts atomic.Int32
+	c := NewClient(WithBaseURL(url), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return next(req)
+			}
+		}))
+	if _, err := c.Query("p", "e", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3 (dial errors are safe to retry)", attempts.Load())
+	}
+}
+
+func TestRetryPolicy_RetryAfterBeyondMaxBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second}))
+	start := time.Now()
+	_, err := c.Query("p", "e", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("err = %v, want ErrRateLimited with RetryAfter 1h", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("calls = %d after %v; must not wait out a long Retry-After", calls.Load(), time.Since(start))
+	}
+}
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-Integrat-TTL", "60")
+		io.WriteString(w, `{"data":{"n":1}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+	params := map[string]any{"a": 1, "b": 2}
+	first, err := c.Query("p", "e", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Query("p", "e", map[string]any{"b": 2, "a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (second served from cache)", calls.Load())
+	}
+	if first.Cached || !second.Cached || string(second.Data) != `{"n":1}` {
+		t.Errorf("first=%+v second=%+v", first, second)
+	}
+
+	if _, err := c.Query("p", "e", map[string]any{"a": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("different params must miss cache: calls = %d", calls.Load())
+	}
+}
+
+func TestCache_SharedBetweenClients(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-Integrat-TTL", "60")
+		io.WriteString(w, `{"data":{"owner":"`+r.Header.Get("Authorization")+`"}}`)
+	})
+	srv, other := httptest.NewServer(handler), httptest.NewServer(handler)
+	defer srv.Close()
+	defer other.Close()
+
+	cache := NewMemoryCache(10)
+	alice := NewClient(WithBaseURL(srv.URL), WithToken("alice"), WithCache(cache))
+	bob := NewClient(WithBaseURL(srv.URL), WithToken("bob"), WithCache(cache))
+	aliceElsewhere := NewClient(WithBaseURL(other.URL), WithToken("alice"), WithCache(cache))
+
+	for _, c := range []*Client{alice, bob, aliceElsewhere, alice} {
+		resp, err := c.Query("p", "e", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"owner":"Bearer ` + c.Token + `"}`; string(resp.Data) != want {
+			t.Errorf("%s@%s got %s", c.Token, c.BaseURL, resp.Data)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3 (only alice's repeat served from cache)", calls.Load())
+	}
+
+	scoped := NewClient(WithBaseURL(srv.URL), WithToken("alice-rotated"), WithCacheScope("user:1"), WithCache(cache))
+	scopedRepeat := NewClient(WithBaseURL(srv.URL), WithToken("alice-next"), WithCacheScope("user:1"), WithCache(cache))
+	scoped.Query("p", "e", nil)
+	if resp, _ := scopedRepeat.Query("p", "e", nil); !resp.Cached {
+		t.Error("same CacheScope must share entries regardless of token")
+	}
+}
+
+func TestMemoryCache_CopiesData(t *testing.T) {
+	m := NewMemoryCache(10)
+	resp := &QueryResponse{Data: json.RawMessage(`{"n":1}`)}
+	m.Set("k", resp, time.Minute)
+	resp.Data[5] = '2'
+
+	got, _ := m.Get("k")
+	got.Data[5] = '3'
+	again, _ := m.Get("k")
+	if string(again.Data) != `{"n":1}` {
+		t.Errorf("cached data mutated: %s", again.Data)
+	}
+}
+
+func TestMemoryCache_ExpiryAndEviction(t *testing.T) {
+	m := NewMemoryCache(2)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+
+	m.Set("a", &QueryResponse{TTL: 1}, time.Second)
+	m.Set("b", &QueryResponse{TTL: 2}, 2*time.Second)
+	m.Set("c", &QueryResponse{TTL: 3}, 3*time.Second)
+	if _, ok := m.Get("a"); ok {
+		t.Error("entry with earliest expiry must be evicted")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := m.Get("b"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok := m.Get("c"); !ok {
+		t.Error("live entry missing")
+	}
+}
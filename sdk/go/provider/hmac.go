@@ -0,0 +1,320 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── HMAC-подпись запросов (auth.type: hmac) ─────────────────────────────
+//
+// Gateway подписывает каждый запрос к провайдеру общим секретом
+// (auth.env). Подпись передаётся в заголовке auth.header
+// (по умолчанию X-Integrat-Signature) в виде "<algorithm>=<hex>", вместе
+// с ней — X-Integrat-Timestamp (unix-секунды) и X-Integrat-Nonce.
+//
+// Подписываемая строка (строки через "\n"):
+//
+//	v1
+//	<timestamp>
+//	<nonce>
+//	<METHOD>
+//	<path?query>
+//	<header>:<value>   — для каждого из signed_headers, имя в нижнем регистре
+//	<hex(sha256(body))>
+
+// Заголовки HMAC-подписи.
+const (
+	SignatureHeader = "X-Integrat-Signature"
+	TimestampHeader = "X-Integrat-Timestamp"
+	NonceHeader     = "X-Integrat-Nonce"
+)
+
+// DefaultTolerance — допустимое расхождение часов gateway и провайдера.
+const DefaultTolerance = 5 * time.Minute
+
+// DefaultMaxBody — предел тела запроса при проверке подписи.
+const DefaultMaxBody = 10 << 20
+
+// Ошибки проверки подписи.
+var (
+	ErrSignatureMissing = errors.New("provider: signature headers missing")
+	ErrSignatureInvalid = errors.New("provider: signature mismatch")
+	ErrTimestampSkew    = errors.New("provider: timestamp outside tolerance")
+	ErrReplay           = errors.New("provider: nonce already used")
+	ErrBodyTooLarge     = errors.New("provider: request body too large")
+)
+
+// HMACConfig — параметры подписи; соответствуют provider.auth в integrat.yaml.
+type HMACConfig struct {
+	Secret        []byte        // значение переменной auth.env
+	Algorithm     string        // sha256 (по умолчанию) или sha512
+	Header        string        // заголовок подписи; по умолчанию SignatureHeader
+	SignedHeaders []string      // auth.signed_headers
+	Tolerance     time.Duration // по умолчанию DefaultTolerance
+	Nonces        NonceStore    // защита от повтора; nil в VerifyHMAC — MemoryNonceStore
+	MaxBody       int64         // предел тела при проверке; по умолчанию DefaultMaxBody
+
+	now func() time.Time // для тестов
+}
+
+func (cfg *HMACConfig) newHash() (func() hash.Hash, error) {
+	switch cfg.Algorithm {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("provider: unsupported hmac algorithm %q", cfg.Algorithm)
+}
+
+func (cfg *HMACConfig) algorithm() string {
+	if cfg.Algorithm == "" {
+		return "sha256"
+	}
+	return cfg.Algorithm
+}
+
+func (cfg *HMACConfig) header() string {
+	if cfg.Header == "" {
+		return SignatureHeader
+	}
+	return cfg.Header
+}
+
+func (cfg *HMACConfig) tolerance() time.Duration {
+	if cfg.Tolerance <= 0 {
+		return DefaultTolerance
+	}
+	return cfg.Tolerance
+}
+
+func (cfg *HMACConfig) maxBody() int64 {
+	if cfg.MaxBody <= 0 {
+		return DefaultMaxBody
+	}
+	return cfg.MaxBody
+}
+
+func (cfg *HMACConfig) clock() time.Time {
+	if cfg.now != nil {
+		return cfg.now()
+	}
+	return time.Now()
+}
+
+// SignRequest подписывает запрос: выставляет заголовки времени, nonce и
+// подписи. Тело читается и восстанавливается. Так подписывает gateway;
+// функция нужна для локальной отладки и тестов провайдера.
+func SignRequest(req *http.Request, cfg HMACConfig) error {
+	newHash, err := cfg.newHash()
+	if err != nil {
+		return err
+	}
+	body, err := readBody(req, 0)
+	if err != nil {
+		return err
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("provider: nonce: %w", err)
+	}
+	ts := strconv.FormatInt(cfg.clock().Unix(), 10)
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(NonceHeader, hex.EncodeToString(nonce[:]))
+
+	mac := sign(newHash, cfg.Secret, canonical(req, cfg.SignedHeaders, body))
+	req.Header.Set(cfg.header(), cfg.algorithm()+"="+hex.EncodeToString(mac))
+	return nil
+}
+
+// Verify проверяет подпись, время и nonce запроса. Тело читается (не более
+// MaxBody — проверка идёт до аутентификации) и восстанавливается. Ошибки:
+// ErrSignatureMissing, ErrSignatureInvalid, ErrTimestampSkew, ErrReplay,
+// ErrBodyTooLarge. Nonce проверяется только при cfg.Nonces != nil.
+func (cfg HMACConfig) Verify(req *http.Request) error {
+	newHash, err := cfg.newHash()
+	if err != nil {
+		return err
+	}
+
+	sig := req.Header.Get(cfg.header())
+	ts := req.Header.Get(TimestampHeader)
+	nonce := req.Header.Get(NonceHeader)
+	if sig == "" || ts == "" || nonce == "" {
+		return ErrSignatureMissing
+	}
+
+	algo, sigHex, ok := strings.Cut(sig, "=")
+	if !ok || algo != cfg.algorithm() {
+		return ErrSignatureInvalid
+	}
+	got, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	skew := cfg.clock().Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > cfg.tolerance() {
+		return ErrTimestampSkew
+	}
+
+	body, err := readBody(req, cfg.maxBody())
+	if err != nil {
+		return err
+	}
+	want := sign(newHash, cfg.Secret, canonical(req, cfg.SignedHeaders, body))
+	if !hmac.Equal(got, want) {
+		return ErrSignatureInvalid
+	}
+
+	// Nonce запоминается только после проверки подписи — иначе чужие
+	// запросы могли бы «занять» nonce настоящего.
+	if cfg.Nonces != nil && cfg.Nonces.Seen(nonce, time.Unix(unix, 0).Add(cfg.tolerance())) {
+		return ErrReplay
+	}
+	return nil
+}
+
+// VerifyHMAC возвращает middleware, которое пропускает к обработчику только
+// запросы с валидной подписью gateway. Остальные получают 401 с кодом
+// "invalid_signature", тело больше MaxBody — 413 "body_too_large". Если
+// cfg.Nonces не задан, используется MemoryNonceStore.
+func VerifyHMAC(cfg HMACConfig) (func(http.Handler) http.Handler, error) {
+	if _, err := cfg.newHash(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Secret) == 0 {
+		return nil, errors.New("provider: hmac secret is empty")
+	}
+	if cfg.Nonces == nil {
+		cfg.Nonces = NewMemoryNonceStore()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := cfg.Verify(r); err != nil {
+				status, code := http.StatusUnauthorized, "invalid_signature"
+				if errors.Is(err, ErrBodyTooLarge) {
+					status, code = http.StatusRequestEntityTooLarge, "body_too_large"
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]string{"code": code, "message": err.Error()})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func canonical(req *http.Request, signed []string, body []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("v1\n")
+	b.WriteString(req.Header.Get(TimestampHeader) + "\n")
+	b.WriteString(req.Header.Get(NonceHeader) + "\n")
+	b.WriteString(req.Method + "\n")
+	b.WriteString(req.URL.RequestURI() + "\n")
+	for _, h := range signed {
+		b.WriteString(strings.ToLower(h) + ":" + strings.TrimSpace(req.Header.Get(h)) + "\n")
+	}
+	sum := sha256.Sum256(body)
+	b.WriteString(hex.EncodeToString(sum[:]))
+	return b.Bytes()
+}
+
+func sign(newHash func() hash.Hash, secret, msg []byte) []byte {
+	m := hmac.New(newHash, secret)
+	m.Write(msg)
+	return m.Sum(nil)
+}
+
+// readBody читает тело целиком и подменяет его копией; limit > 0 —
+// предел размера.
+func readBody(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if limit > 0 && req.ContentLength > limit {
+		return nil, ErrBodyTooLarge
+	}
+	r := io.Reader(req.Body)
+	if limit > 0 {
+		r = io.LimitReader(req.Body, limit+1)
+	}
+	body, err := io.ReadAll(r)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("provider: read body: %w", err)
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ── Защита от повтора ───────────────────────────────────────────────────
+
+// NonceStore запоминает использованные nonce.
+type NonceStore interface {
+	// Seen запоминает nonce до expires и сообщает, встречался ли он раньше.
+	Seen(nonce string, expires time.Time) bool
+}
+
+// MemoryNonceStore — NonceStore в памяти процесса. Просроченные nonce
+// удаляются при обращениях. Для нескольких реплик провайдера нужна общая
+// реализация (например, на Redis с SET NX EX).
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	now    func() time.Time
+	sweeps int
+}
+
+// NewMemoryNonceStore создаёт пустое хранилище.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{seen: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemoryNonceStore) Seen(nonce string, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.sweeps++; s.sweeps >= 1000 {
+		s.sweeps = 0
+		for n, exp := range s.seen {
+			if now.After(exp) {
+				delete(s.seen, n)
+			}
+		}
+	}
+
+	if exp, ok := s.seen[nonce]; ok && !now.After(exp) {
+		return true
+	}
+	s.seen[nonce] = expires
+	return false
+}
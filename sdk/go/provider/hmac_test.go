@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedRequest(t *testing.T, cfg HMACConfig, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/posts?limit=10", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := SignRequest(req, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func testHMACConfig() HMACConfig {
+	return HMACConfig{
+		Secret:        []byte("s3cr3t"),
+		SignedHeaders: []string{"Content-Type"},
+	}
+}
+
+func TestHMAC_SignVerify(t *testing.T) {
+	for _, algo := range []string{"", "sha256", "sha512"} {
+		cfg := testHMACConfig()
+		cfg.Algorithm = algo
+		req := signedRequest(t, cfg, `{"q":1}`)
+		if err := cfg.Verify(req); err != nil {
+			t.Errorf("algorithm %q: %v", algo, err)
+		}
+		// Тело восстановлено для обработчика.
+		if b, _ := io.ReadAll(req.Body); string(b) != `{"q":1}` {
+			t.Errorf("body after verify = %q", b)
+		}
+	}
+}
+
+func TestHMAC_Tampered(t *testing.T) {
+	cfg := testHMACConfig()
+
+	tests := map[string]func(r *http.Request){
+		"body":   func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"q":2}`)) },
+		"query":  func(r *http.Request) { r.URL.RawQuery = "limit=1000" },
+		"header": func(r *http.Request) { r.Header.Set("Content-Type", "text/plain") },
+		"method": func(r *http.Request) { r.Method = "PUT" },
+	}
+	for name, tamper := range tests {
+		req := signedRequest(t, cfg, `{"q":1}`)
+		tamper(req)
+		if err := cfg.Verify(req); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("%s: err = %v, want ErrSignatureInvalid", name, err)
+		}
+	}
+
+	other := cfg
+	other.Secret = []byte("other")
+	if err := other.Verify(signedRequest(t, cfg, "")); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("wrong secret: err = %v", err)
+	}
+}
+
+func TestHMAC_Missing(t *testing.T) {
+	cfg := testHMACConfig()
+	req := httptest.NewRequest("GET", "/", nil)
+	if err := cfg.Verify(req); !errors.Is(err, ErrSignatureMissing) {
+		t.Errorf("err = %v, want ErrSignatureMissing", err)
+	}
+}
+
+func TestHMAC_TimestampSkew(t *testing.T) {
+	cfg := testHMACConfig()
+	cfg.Tolerance = time.Minute
+	cfg.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	req := signedRequest(t, cfg, "")
+
+	cfg.now = nil
+	if err := cfg.Verify(req); !errors.Is(err, ErrTimestampSkew) {
+		t.Errorf("err = %v, want ErrTimestampSkew", err)
+	}
+}
+
+func TestVerifyHMAC_Middleware(t *testing.T) {
+	cfg := testHMACConfig()
+	mw, err := VerifyHMAC(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+
+	req := signedRequest(t, cfg, `{"q":1}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"q":1}`))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("signed request: status %d, calls %d", rec.Code, calls)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, replay)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "nonce already used") {
+		t.Errorf("replay: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusUnauthorized || calls != 1 {
+		t.Errorf("unsigned: status %d, calls %d", rec.Code, calls)
+	}
+}
+
+func TestVerifyHMAC_BodyLimit(t *testing.T) {
+	cfg := testHMACConfig()
+	cfg.MaxBody = 16
+	mw, err := VerifyHMAC(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := strings.Repeat("x", 64)
+	for name, chunked := range map[string]bool{"content-length": false, "chunked": true} {
+		// Предел действует и при неизвестной длине тела (chunked).
+		req := signedRequest(t, cfg, body)
+		if chunked {
+			req.ContentLength = -1
+			req.Body = io.NopCloser(strings.NewReader(body))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "body_too_large") {
+			t.Errorf("%s: status %d, body %s", name, rec.Code, rec.Body)
+		}
+	}
+
+	if err := cfg.Verify(signedRequest(t, cfg, `{"q":1}`)); err != nil {
+		t.Errorf("body within limit: %v", err)
+	}
+}
+
+func TestVerifyHMAC_Config(t *testing.T) {
+	if _, err := VerifyHMAC(HMACConfig{}); err == nil {
+		t.Error("expected error for empty secret")
+	}
+	if _, err := VerifyHMAC(HMACConfig{Secret: []byte("x"), Algorithm: "md5"}); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
+
+func TestMemoryNonceStore_Expiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryNonceStore()
+	s.now = func() time.Time { return now }
+
+	if s.Seen("a", now.Add(time.Minute)) {
+		t.Fatal("first use reported as seen")
+	}
+	if !s.Seen("a", now.Add(time.Minute)) {
+		t.Fatal("second use not detected")
+	}
+	now = now.Add(2 * time.Minute)
+	if s.Seen("a", now.Add(time.Minute)) {
+		t.Error("expired nonce still reported as seen")
+	}
+}
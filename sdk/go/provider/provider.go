@@ -0,0 +1,109 @@
+// Пакет provider — помощники для HTTP-сервисов провайдеров Integrat.
+//
+// ValidateResponse проверяет ответы обработчика по response_schema эндпоинта
+// из integrat.yaml до того, как их получит gateway:
+//
+//	mw, err := provider.ValidateResponse(schema)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	mux.Handle("/v1/posts", mw(postsHandler))
+//
+// VerifyHMAC проверяет подпись gateway при auth.type: hmac (с защитой от
+// повтора запросов):
+//
+//	verify, err := provider.VerifyHMAC(provider.HMACConfig{
+//		Secret:        []byte(os.Getenv("GATEWAY_SECRET")),
+//		SignedHeaders: []string{"Content-Type"},
+//	})
+//	http.ListenAndServe(":8080", verify(mux))
+//
+// EventWriter отдаёт обновления эндпоинта с subscribe: true в формате
+// Server-Sent Events; после обрыва gateway переподключается с LastEventID.
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/plagness/Integrat/sdk/go/internal/jsonschema"
+)
+
+// ── Проверка ответов ────────────────────────────────────────────────────
+
+// ValidateResponse возвращает middleware, которое буферизует ответ
+// обработчика и проверяет JSON-тело успешных (2xx) ответов по JSON Schema.
+// Ответ, не прошедший проверку, заменяется на 500 с кодом
+// "invalid_response" и списком ошибок. Ответы с ошибками (не 2xx)
+// пропускаются как есть.
+//
+// Ответ буферизуется целиком — для потоковых эндпоинтов (streaming: true)
+// middleware не подходит.
+func ValidateResponse(schema json.RawMessage) (func(http.Handler) http.Handler, error) {
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("provider: unmarshal response_schema: %w", err)
+	}
+	if errs := jsonschema.Check(s); len(errs) > 0 {
+		return nil, fmt.Errorf("provider: invalid response_schema: %s", joinErrors(errs))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &recorder{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status < 200 || rec.status > 299 || rec.body.Len() == 0 {
+				rec.flush(w)
+				return
+			}
+
+			var v any
+			if err := json.Unmarshal(rec.body.Bytes(), &v); err != nil {
+				writeInvalid(w, "response is not valid JSON: "+err.Error())
+				return
+			}
+			if errs := jsonschema.Validate(s, v); len(errs) > 0 {
+				writeInvalid(w, "response does not match response_schema: "+joinErrors(errs))
+				return
+			}
+			rec.flush(w)
+		})
+	}, nil
+}
+
+// recorder буферизует статус и тело ответа. Заголовки пишутся сразу
+// в исходный ResponseWriter.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header         { return r.header }
+func (r *recorder) WriteHeader(status int)      { r.status = status }
+func (r *recorder) Write(p []byte) (int, error) { return r.body.Write(p) }
+
+func (r *recorder) flush(w http.ResponseWriter) {
+	w.WriteHeader(r.status)
+	w.Write(r.body.Bytes())
+}
+
+func writeInvalid(w http.ResponseWriter, msg string) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{"code": "invalid_response", "message": msg})
+}
+
+func joinErrors(errs []jsonschema.Error) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.StringEN()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const postsSchema = `{"type":"array","items":{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}}`
+
+func serve(t *testing.T, status int, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mw, err := ValidateResponse(json.RawMessage(postsSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/posts", nil))
+	return rec
+}
+
+func TestValidateResponse_Valid(t *testing.T) {
+	rec := serve(t, http.StatusOK, `[{"id":1}]`)
+	if rec.Code != http.StatusOK || rec.Body.String() != `[{"id":1}]` {
+		t.Errorf("got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidateResponse_Invalid(t *testing.T) {
+	rec := serve(t, http.StatusOK, `[{"id":"x"}]`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var body struct{ Code, Message string }
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Code != "invalid_response" || !strings.Contains(body.Message, "[0].id: must be integer, got string") {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestValidateResponse_NotJSON(t *testing.T) {
+	if rec := serve(t, http.StatusOK, `oops`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d", rec.Code)
+	}
+}
+
+func TestValidateResponse_ErrorPassthrough(t *testing.T) {
+	rec := serve(t, http.StatusNotFound, `{"error":"not found"}`)
+	if rec.Code != http.StatusNotFound || rec.Body.String() != `{"error":"not found"}` {
+		t.Errorf("got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidateResponse_BadSchema(t *testing.T) {
+	if _, err := ValidateResponse(json.RawMessage(`{"type":"list"}`)); err == nil {
+		t.Error("expected error for invalid schema")
+	}
+}
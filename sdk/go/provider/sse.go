@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ── Server-Sent Events ──────────────────────────────────────────────────
+
+// EventWriter пишет обновления эндпоинта с subscribe: true в формате
+// Server-Sent Events. Gateway держит соединение и пересылает события
+// подписчикам; после обрыва он переподключается с заголовком Last-Event-ID
+// (см. LastEventID) — пропущенные события стоит дослать.
+//
+//	ew, err := provider.NewEventWriter(w)
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusInternalServerError)
+//		return
+//	}
+//	for msg := range messages {
+//		if err := ew.Send(strconv.FormatInt(msg.ID, 10), "message", msg); err != nil {
+//			return
+//		}
+//	}
+type EventWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+// NewEventWriter отправляет заголовки text/event-stream и возвращает
+// EventWriter. ResponseWriter должен поддерживать http.Flusher.
+func NewEventWriter(w http.ResponseWriter) (*EventWriter, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("provider: response writer does not support flushing")
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &EventWriter{w: w, f: f}, nil
+}
+
+// Send кодирует data в JSON и отправляет событие. Пустой id не меняет
+// Last-Event-ID, пустой event — тип "message".
+func (e *EventWriter) Send(id, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("provider: marshal event: %w", err)
+	}
+	var buf bytes.Buffer
+	if id != "" {
+		buf.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	// json.Marshal не оставляет переводов строки — данные в одной строке.
+	buf.WriteString("data: ")
+	buf.Write(b)
+	buf.WriteString("\n\n")
+	return e.write(buf.Bytes())
+}
+
+// Retry сообщает gateway паузу перед переподключением после обрыва.
+func (e *EventWriter) Retry(d time.Duration) error {
+	return e.write([]byte("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n"))
+}
+
+// Ping отправляет комментарий, чтобы прокси не закрыли простаивающее
+// соединение.
+func (e *EventWriter) Ping() error {
+	return e.write([]byte(": ping\n\n"))
+}
+
+func (e *EventWriter) write(p []byte) error {
+	if _, err := e.w.Write(p); err != nil {
+		return fmt.Errorf("provider: write event: %w", err)
+	}
+	e.f.Flush()
+	return nil
+}
+
+// LastEventID возвращает id последнего события, полученного gateway до
+// обрыва, или "" для нового подключения.
+func LastEventID(r *http.Request) string {
+	return r.Header.Get("Last-Event-ID")
+}
@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEventWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ew, err := NewEventWriter(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ew.Retry(3 * time.Second)
+	ew.Send("7", "", map[string]any{"text": "a\nb"})
+	ew.Ping()
+	ew.Send("", "delete", 7)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want := "retry: 3000\n\nid: 7\ndata: {\"text\":\"a\\nb\"}\n\n: ping\n\nevent: delete\ndata: 7\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestLastEventID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/posts", nil)
+	if id := LastEventID(r); id != "" {
+		t.Errorf("id = %q", id)
+	}
+	r.Header.Set("Last-Event-ID", "42")
+	if id := LastEventID(r); id != "42" {
+		t.Errorf("id = %q", id)
+	}
+}
@@ -0,0 +1,166 @@
+// Повтор запросов при временных ошибках: сбой транспорта, 429, 502-504.
+package integrat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy — политика повторов. Идемпотентные вызовы (GET, PUT,
+// DELETE) повторяются при сбое транспорта, 429 и 502-504. Запрос данных
+// POST /v1/query тарифицируется после ответа провайдера, поэтому
+// повторяется только при 429, 503 и ошибке соединения до отправки запроса.
+// Retry-After дольше MaxBackoff не выжидается — вызов завершается с
+// ErrRateLimited. Нулевое значение — без повторов.
+type RetryPolicy struct {
+	MaxAttempts int           // всего попыток, включая первую (<= 1 — без повторов)
+	MinBackoff  time.Duration // пауза перед первым повтором (по умолчанию 200ms)
+	MaxBackoff  time.Duration // верхняя граница паузы (по умолчанию 5s)
+}
+
+// DefaultRetryPolicy — 3 попытки с экспоненциальной паузой 200ms…5s.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// backoff возвращает паузу перед попыткой attempt (1 — первый повтор):
+// экспонента с джиттером ±25%, ограниченная MaxBackoff.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	min, max := p.MinBackoff, p.maxBackoff()
+	if min <= 0 {
+		min = 200 * time.Millisecond
+	}
+	d := min << (attempt - 1)
+	if d <= 0 || d > max {
+		d = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2+1)) - d/4
+	return d + jitter
+}
+
+func (p RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return 5 * time.Second
+	}
+	return p.MaxBackoff
+}
+
+// idempotent — вызовы, повтор которых безопасен при любом временном сбое.
+func idempotent(call *CallInfo) bool {
+	switch call.Method {
+	case "GET", "HEAD", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// billedQuery — платный запрос данных: 502/504 или обрыв могут прийти
+// уже после вызова провайдера и списания.
+func billedQuery(call *CallInfo) bool {
+	return call.Method == "POST" && call.Path == "/v1/query"
+}
+
+// retryable сообщает, можно ли повторить вызов после исхода resp/err.
+func retryable(call *CallInfo, resp *http.Response, err error) bool {
+	switch {
+	case idempotent(call):
+		return err != nil || retryableStatus(resp.StatusCode)
+	case billedQuery(call):
+		if err != nil {
+			return notSent(err)
+		}
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// retryableStatus — временные HTTP-статусы.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 502 && status <= 504)
+}
+
+// notSent сообщает, что запрос не ушёл: соединение не установлено.
+func notSent(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// doRetry отправляет запрос через do, повторяя его по RetryPolicy.
+func (c *Client) doRetry(req *http.Request, call *CallInfo) (*http.Response, error) {
+	attempts := c.Retry.MaxAttempts
+	if attempts <= 1 || !(idempotent(call) || billedQuery(call)) {
+		return c.do(req, call)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(req, call)
+		if attempt >= attempts || !retryable(call, resp, err) {
+			return resp, err
+		}
+		if err != nil && ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := c.Retry.backoff(attempt)
+		attrs := append(callAttrs(call), slog.Int("attempt", attempt))
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		} else {
+			if ra := retryAfter(resp.Header); ra > 0 {
+				// Ждать дольше MaxBackoff не станем: вызывающий получит
+				// ErrRateLimited с RetryAfter и решит сам.
+				if ra > c.Retry.maxBackoff() {
+					return resp, nil
+				}
+				wait = ra
+			}
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		attrs = append(attrs, slog.Duration("backoff", wait))
+		c.log(ctx, slog.LevelDebug, "integrat retry", attrs...)
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("integrat: retry: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter разбирает заголовок Retry-After в секундах.
+func retryAfter(h http.Header) time.Duration {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
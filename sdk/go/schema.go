@@ -0,0 +1,248 @@
+// Схемы эндпоинтов: описания эндпоинтов из маркетплейса кешируются в клиенте
+// и используются для локальной проверки параметров по params_schema и
+// ответов по response_schema (строгий режим).
+package integrat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plagness/Integrat/sdk/go/internal/jsonschema"
+)
+
+var (
+	// ErrInvalidParams — параметры запроса не соответствуют params_schema эндпоинта.
+	ErrInvalidParams = errors.New("integrat: params do not match params_schema")
+	// ErrInvalidResponse — данные ответа не соответствуют response_schema эндпоинта.
+	ErrInvalidResponse = errors.New("integrat: response does not match response_schema")
+)
+
+// ParamsError — параметры не прошли локальную проверку по params_schema.
+// Field в элементах Fields — путь к параметру: "params.limit".
+type ParamsError struct {
+	Plugin   string
+	Endpoint string
+	Fields   []FieldError
+}
+
+func (e *ParamsError) Error() string {
+	return fmt.Sprintf("integrat: %s/%s: params do not match params_schema: %s",
+		e.Plugin, e.Endpoint, joinFieldErrors(e.Fields))
+}
+
+func (e *ParamsError) Unwrap() error { return ErrInvalidParams }
+
+// ResponseError — ответ не прошёл проверку по response_schema.
+// Field в элементах Fields — путь внутри данных: "data", "data[0].text".
+type ResponseError struct {
+	Plugin   string
+	Endpoint string
+	Fields   []FieldError
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("integrat: %s/%s: response does not match response_schema: %s",
+		e.Plugin, e.Endpoint, joinFieldErrors(e.Fields))
+}
+
+func (e *ResponseError) Unwrap() error { return ErrInvalidResponse }
+
+// ValidateResponse проверяет данные ответа по ResponseSchema эндпоинта.
+// Без схемы возвращает nil. При несоответствии — *ResponseError.
+func (e *Endpoint) ValidateResponse(data json.RawMessage) error {
+	if len(e.ResponseSchema) == 0 || string(e.ResponseSchema) == "null" {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(e.ResponseSchema, &schema); err != nil {
+		return fmt.Errorf("integrat: unmarshal response_schema: %w", err)
+	}
+	var v any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("integrat: unmarshal data: %w", err)
+		}
+	}
+
+	if errs := jsonschema.Validate(schema, v); len(errs) > 0 {
+		return &ResponseError{Endpoint: e.Slug, Fields: schemaFieldErrors("data", errs)}
+	}
+	return nil
+}
+
+// ValidateParams проверяет параметры по ParamsSchema эндпоинта и возвращает
+// их копию с подставленными default из схемы. Без схемы params возвращаются
+// как есть. При несоответствии — *ParamsError.
+func (e *Endpoint) ValidateParams(params map[string]any) (map[string]any, error) {
+	if len(e.ParamsSchema) == 0 || string(e.ParamsSchema) == "null" {
+		return params, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(e.ParamsSchema, &schema); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal params_schema: %w", err)
+	}
+
+	// Приводим значения Go ([]string, int, структуры) к JSON-представлению —
+	// так их увидит провайдер. UseNumber сохраняет точность больших чисел.
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: marshal params: %w", err)
+	}
+	norm := map[string]any{}
+	if params != nil {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&norm); err != nil {
+			return nil, fmt.Errorf("integrat: unmarshal params: %w", err)
+		}
+	}
+
+	jsonschema.ApplyDefaults(schema, norm)
+	if errs := jsonschema.Validate(schema, norm); len(errs) > 0 {
+		return nil, &ParamsError{Endpoint: e.Slug, Fields: schemaFieldErrors("params", errs)}
+	}
+	return norm, nil
+}
+
+func schemaFieldErrors(root string, errs []jsonschema.Error) []FieldError {
+	fields := make([]FieldError, len(errs))
+	for i, se := range errs {
+		path := root
+		switch {
+		case se.Path == "":
+		case strings.HasPrefix(se.Path, "["):
+			path += se.Path
+		default:
+			path += "." + se.Path
+		}
+		fields[i] = FieldError{Field: path, Code: se.Code, Message: se.MessageEN}
+	}
+	return fields
+}
+
+func joinFieldErrors(fields []FieldError) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ── Кеш описаний эндпоинтов ─────────────────────────────────────────────
+
+// DefaultSchemaTTL — срок кеша описаний эндпоинтов по умолчанию.
+const DefaultSchemaTTL = 10 * time.Minute
+
+// endpointCache — описания эндпоинтов по slug плагина. Заполняется из
+// GetPluginBySlug при первом обращении и обновляется по истечении
+// Client.SchemaTTL или после InvalidateSchemas.
+type endpointCache struct {
+	mu      sync.Mutex
+	plugins map[string]schemaEntry
+}
+
+type schemaEntry struct {
+	eps     map[string]*Endpoint // nil — описание недоступно, проверка пропускается
+	expires time.Time
+}
+
+// InvalidateSchemas сбрасывает кеш описаний эндпоинтов плагина (пустой
+// plugin — всех плагинов): следующий запрос загрузит params_schema и
+// response_schema заново.
+func (c *Client) InvalidateSchemas(plugin string) {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+	if plugin == "" {
+		c.endpoints.plugins = nil
+		return
+	}
+	delete(c.endpoints.plugins, plugin)
+}
+
+// endpoint возвращает описание эндпоинта плагина. Если плагин найден, но
+// эндпоинта в нём нет, возвращает nil без ошибки. Плагин, которого нет в
+// маркетплейсе или который скрыт от вызывающего (404, 403), тоже даёт nil:
+// запрос к нему может быть разрешён, проверку выполнит gateway.
+func (c *Client) endpoint(ctx context.Context, plugin, slug string) (*Endpoint, error) {
+	now := time.Now()
+	c.endpoints.mu.Lock()
+	entry, ok := c.endpoints.plugins[plugin]
+	c.endpoints.mu.Unlock()
+
+	if !ok || !now.Before(entry.expires) {
+		detail, err := c.pluginBySlug(ctx, plugin)
+		switch {
+		case errors.Is(err, ErrNotFound), errors.Is(err, ErrForbidden):
+			c.log(ctx, slog.LevelDebug, "integrat: endpoint schemas unavailable, local validation skipped",
+				slog.String("plugin", plugin), slog.String("error", err.Error()))
+			entry = schemaEntry{}
+		case err != nil:
+			return nil, err
+		default:
+			entry.eps = make(map[string]*Endpoint, len(detail.Endpoints))
+			for i := range detail.Endpoints {
+				entry.eps[detail.Endpoints[i].Slug] = &detail.Endpoints[i]
+			}
+		}
+
+		ttl := c.SchemaTTL
+		if ttl <= 0 {
+			ttl = DefaultSchemaTTL
+		}
+		entry.expires = now.Add(ttl)
+		c.endpoints.mu.Lock()
+		if c.endpoints.plugins == nil {
+			c.endpoints.plugins = make(map[string]schemaEntry)
+		}
+		c.endpoints.plugins[plugin] = entry
+		c.endpoints.mu.Unlock()
+	}
+	return entry.eps[slug], nil
+}
+
+// checkResponse проверяет данные ответа по response_schema эндпоинта
+// (используется при StrictResponses).
+func (c *Client) checkResponse(ctx context.Context, call *CallInfo, data json.RawMessage) error {
+	ep, err := c.endpoint(ctx, call.Plugin, call.Endpoint)
+	if err != nil {
+		return fmt.Errorf("integrat: load response_schema for %s/%s: %w", call.Plugin, call.Endpoint, err)
+	}
+	if ep == nil {
+		return nil
+	}
+	err = ep.ValidateResponse(data)
+	var re *ResponseError
+	if errors.As(err, &re) {
+		re.Plugin = call.Plugin
+		c.log(ctx, slog.LevelWarn, "integrat: response does not match response_schema",
+			append(callAttrs(call), slog.Int("errors", len(re.Fields)))...)
+	}
+	return err
+}
+
+// checkParams проверяет параметры по params_schema эндпоинта и подставляет
+// default (используется при ValidateParams).
+func (c *Client) checkParams(ctx context.Context, call *CallInfo, params map[string]any) (map[string]any, error) {
+	ep, err := c.endpoint(ctx, call.Plugin, call.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: load params_schema for %s/%s: %w", call.Plugin, call.Endpoint, err)
+	}
+	if ep == nil {
+		return params, nil
+	}
+	out, err := ep.ValidateParams(params)
+	var pe *ParamsError
+	if errors.As(err, &pe) {
+		pe.Plugin = call.Plugin
+		c.log(ctx, slog.LevelDebug, "integrat: params do not match params_schema",
+			append(callAttrs(call), slog.Int("errors", len(pe.Fields)))...)
+	}
+	return out, err
+}
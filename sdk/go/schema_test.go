@@ -0,0 +1,226 @@
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testResponseSchema = `{"type":"array","items":{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}}`
+
+func schemaServer(t *testing.T, data string, lookups *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/marketplace/p":
+			atomic.AddInt32(lookups, 1)
+			io.WriteString(w, `{"plugin":{"slug":"p"},"endpoints":[{"slug":"e","response_schema":`+testResponseSchema+`}]}`)
+		case "/v1/query":
+			io.WriteString(w, `{"data":`+data+`}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStrictResponses_Valid(t *testing.T) {
+	var lookups int32
+	srv := schemaServer(t, `[{"id":1},{"id":2}]`, &lookups)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithStrictResponses())
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Query("p", "e", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("marketplace lookups = %d, want 1 (cached)", n)
+	}
+}
+
+func TestStrictResponses_Invalid(t *testing.T) {
+	var lookups int32
+	srv := schemaServer(t, `[{"id":1},{"id":"two"},{}]`, &lookups)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithStrictResponses())
+
+	_, err := c.Query("p", "e", nil)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+	var re *ResponseError
+	if !errors.As(err, &re) {
+		t.Fatalf("err = %T, want *ResponseError", err)
+	}
+	if re.Plugin != "p" || re.Endpoint != "e" || len(re.Fields) != 2 {
+		t.Errorf("ResponseError = %+v", re)
+	}
+	if re.Fields[0].Field != "data[1].id" || re.Fields[1].Field != "data[2].id" {
+		t.Errorf("fields = %v", re.Fields)
+	}
+	if re.Fields[0].Code != "invalid_type" || re.Fields[1].Code != "required" {
+		t.Errorf("codes = %+v", re.Fields)
+	}
+	want := "integrat: p/e: response does not match response_schema: data[1].id: must be integer, got string; data[2].id: required"
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestStrictResponses_Disabled(t *testing.T) {
+	var lookups int32
+	srv := schemaServer(t, `{"not":"an array"}`, &lookups)
+	c := NewWithURL("t", srv.URL)
+
+	if _, err := c.Query("p", "e", nil); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 0 {
+		t.Error("schema loaded without StrictResponses")
+	}
+}
+
+func TestEndpoint_ValidateResponse_NoSchema(t *testing.T) {
+	ep := &Endpoint{Slug: "e"}
+	if err := ep.ValidateResponse(json.RawMessage(`"anything"`)); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
+
+const testParamsSchema = `{"type":"object","required":["query"],"properties":{
+  "query":{"type":"string","minLength":1},
+  "limit":{"type":"integer","maximum":500,"default":50}}}`
+
+func paramsServer(t *testing.T, sent *map[string]any, queries *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/marketplace/p":
+			io.WriteString(w, `{"plugin":{"slug":"p"},"endpoints":[{"slug":"search","params_schema":`+testParamsSchema+`}]}`)
+		case "/v1/query":
+			atomic.AddInt32(queries, 1)
+			var qr QueryRequest
+			json.NewDecoder(r.Body).Decode(&qr)
+			*sent = qr.Params
+			io.WriteString(w, `{"data":[]}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValidateParams_Defaults(t *testing.T) {
+	var sent map[string]any
+	var queries int32
+	srv := paramsServer(t, &sent, &queries)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithParamsValidation())
+
+	params := map[string]any{"query": "go"}
+	if _, err := c.Query("p", "search", params); err != nil {
+		t.Fatal(err)
+	}
+	if sent["limit"] != float64(50) || sent["query"] != "go" {
+		t.Errorf("sent params = %v", sent)
+	}
+	if _, ok := params["limit"]; ok {
+		t.Error("caller's params map was modified")
+	}
+}
+
+func TestValidateParams_Invalid(t *testing.T) {
+	var sent map[string]any
+	var queries int32
+	srv := paramsServer(t, &sent, &queries)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithParamsValidation())
+
+	_, err := c.Query("p", "search", map[string]any{"limit": 1000})
+	var pe *ParamsError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("err = %v, want *ParamsError", err)
+	}
+	got := map[string]string{}
+	for _, f := range pe.Fields {
+		got[f.Field] = f.Code
+	}
+	if got["params.query"] != "required" || got["params.limit"] != "out_of_range" || pe.Plugin != "p" || pe.Endpoint != "search" {
+		t.Errorf("ParamsError = %+v", pe)
+	}
+	want := "integrat: p/search: params do not match params_schema: params.query: required; params.limit: must be <= 500"
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if queries != 0 {
+		t.Error("invalid params were sent to API")
+	}
+}
+
+func TestValidateParams_Stream(t *testing.T) {
+	var sent map[string]any
+	var queries int32
+	srv := paramsServer(t, &sent, &queries)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithParamsValidation())
+
+	_, err := c.OpenStream(context.Background(), "p", "search", 0, map[string]any{"query": ""})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("err = %v, want ErrInvalidParams", err)
+	}
+}
+
+func TestValidateParams_PluginHidden(t *testing.T) {
+	var lookups, queries int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/marketplace/private":
+			atomic.AddInt32(&lookups, 1)
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"not found"}`)
+		case "/v1/query":
+			atomic.AddInt32(&queries, 1)
+			io.WriteString(w, `{"data":[]}`)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithParamsValidation(), WithStrictResponses())
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Query("private", "e", map[string]any{"x": 1}); err != nil {
+			t.Fatalf("query %d: %v", i, err)
+		}
+	}
+	if lookups != 1 || queries != 2 {
+		t.Errorf("lookups = %d, queries = %d; want 1 cached lookup, 2 queries", lookups, queries)
+	}
+}
+
+func TestSchemaCache_Refresh(t *testing.T) {
+	var lookups int32
+	srv := schemaServer(t, `[{"id":1}]`, &lookups)
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), WithStrictResponses())
+
+	c.Query("p", "e", nil)
+	c.InvalidateSchemas("p")
+	c.Query("p", "e", nil)
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("lookups after InvalidateSchemas = %d, want 2", n)
+	}
+
+	c.SchemaTTL = time.Nanosecond
+	c.InvalidateSchemas("")
+	c.Query("p", "e", nil)
+	time.Sleep(time.Millisecond)
+	c.Query("p", "e", nil)
+	if n := atomic.LoadInt32(&lookups); n != 4 {
+		t.Errorf("lookups after SchemaTTL expiry = %d, want 4", n)
+	}
+}
@@ -0,0 +1,71 @@
+// Режимы работы gateway с провайдером (provider.proxy_mode) и загрузка
+// снимков данных для режима push: провайдер сам отправляет данные, а
+// gateway отдаёт последний снимок на запросы эндпоинта.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Режимы provider.proxy_mode.
+const (
+	ProxyModeProxy    = "proxy"    // gateway проксирует запросы на base_url + path
+	ProxyModeRedirect = "redirect" // gateway отвечает 307, клиент идёт к провайдеру сам
+	ProxyModePush     = "push"     // провайдер загружает снимки через PushSnapshot
+)
+
+// Snapshot — снимок данных эндпоинта в режиме push.
+type Snapshot struct {
+	PluginID   int64           `json:"plugin_id"`
+	EndpointID int64           `json:"endpoint_id"`
+	Data       json.RawMessage `json:"data"`
+	Version    int64           `json:"version"`              // растёт с каждой загрузкой
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"` // после — эндпоинт отвечает 503
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// PushSnapshotParams — параметры загрузки снимка.
+type PushSnapshotParams struct {
+	Data any `json:"data"`
+	// TTL — срок актуальности снимка, округляется вверх до целых секунд;
+	// 0 — без ограничения.
+	TTL time.Duration `json:"-"`
+}
+
+// PushSnapshot загружает снимок данных эндпоинта плагина с proxy_mode: push.
+// Новый снимок заменяет предыдущий целиком.
+func (c *Client) PushSnapshot(pluginID, endpointID int64, params PushSnapshotParams) (*Snapshot, error) {
+	if params.TTL < 0 {
+		return nil, fmt.Errorf("integrat: snapshot ttl must be >= 0")
+	}
+	body := struct {
+		Data any `json:"data"`
+		TTL  int `json:"ttl,omitempty"`
+	}{params.Data, int((params.TTL + time.Second - 1) / time.Second)}
+
+	respBody, _, err := c.doJSON(context.Background(), "PUT", fmt.Sprintf("/v1/plugins/%d/endpoints/%d/snapshot", pluginID, endpointID), body)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(respBody, &snap); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &snap, nil
+}
+
+// GetSnapshot возвращает текущий снимок эндпоинта.
+func (c *Client) GetSnapshot(pluginID, endpointID int64) (*Snapshot, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/plugins/%d/endpoints/%d/snapshot", pluginID, endpointID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(respBody, &snap); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &snap, nil
+}
@@ -0,0 +1,80 @@
+package integrat
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPushSnapshot(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/v1/plugins/3/endpoints/7/snapshot" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"plugin_id":3,"endpoint_id":7,"data":[{"rate":91.5}],"version":4,"updated_at":"2026-10-01T12:00:00Z"}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	snap, err := c.PushSnapshot(3, 7, PushSnapshotParams{
+		Data: []map[string]any{{"rate": 91.5}},
+		TTL:  10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["ttl"] != float64(600) || got["data"] == nil {
+		t.Errorf("request = %v", got)
+	}
+	if snap.Version != 4 || string(snap.Data) != `[{"rate":91.5}]` {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestPushSnapshot_NoTTL(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"version":1}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	if _, err := c.PushSnapshot(1, 1, PushSnapshotParams{Data: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["ttl"]; ok {
+		t.Errorf("ttl sent without TTL: %v", got)
+	}
+	if _, err := c.PushSnapshot(1, 1, PushSnapshotParams{TTL: -time.Second}); err == nil {
+		t.Error("expected error for negative ttl")
+	}
+}
+
+func TestPushSnapshot_SubSecondTTL(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"version":1}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	for ttl, want := range map[time.Duration]float64{
+		time.Nanosecond:         1,
+		500 * time.Millisecond:  1,
+		1500 * time.Millisecond: 2,
+	} {
+		if _, err := c.PushSnapshot(1, 1, PushSnapshotParams{Data: "x", TTL: ttl}); err != nil {
+			t.Fatal(err)
+		}
+		if got["ttl"] != want {
+			t.Errorf("ttl %v sent as %v, want %v", ttl, got["ttl"], want)
+		}
+	}
+}
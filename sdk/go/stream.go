@@ -0,0 +1,349 @@
+// Потоковое чтение ответов /v1/query для больших эндпоинтов (streaming: true).
+// Поддерживает JSON-массив, объект {"data": [...]} и NDJSON.
+package integrat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrStopStream возвращается из колбэка QueryStream для досрочной остановки
+// чтения без ошибки.
+var ErrStopStream = errors.New("integrat: stop stream")
+
+// Stream — итератор по элементам потокового ответа.
+//
+//	s, err := client.OpenStream(ctx, "channel-mcp", "messages.fetch", 0, params)
+//	if err != nil { ... }
+//	defer s.Close()
+//	for s.Next() {
+//	    var msg Message
+//	    _ = json.Unmarshal(s.Item(), &msg)
+//	}
+//	if err := s.Err(); err != nil { ... }
+type Stream struct {
+	Cached bool // ответ из кеша (X-Integrat-Cached)
+	Stale  bool // данные устарели (X-Integrat-Stale)
+
+	body    io.ReadCloser
+	counter *countingReader
+	finish  func()
+	dec     *json.Decoder
+	ndjson  bool
+	started bool
+	inArray bool
+	single  json.RawMessage
+	done    bool
+	item    json.RawMessage
+	err     error
+}
+
+// OpenStream выполняет запрос к потоковому эндпоинту и возвращает итератор.
+// Вызывающий обязан закрыть Stream. При chatID == 0 используется DefaultChatID.
+func (c *Client) OpenStream(ctx context.Context, plugin, endpoint string, chatID int64, params map[string]any) (*Stream, error) {
+	if chatID == 0 {
+		chatID = c.DefaultChatID
+	}
+	call := &CallInfo{Method: "POST", Path: "/v1/query", Plugin: plugin, Endpoint: endpoint, Stream: true}
+	if c.ValidateParams {
+		var err error
+		if params, err = c.checkParams(ctx, call, params); err != nil {
+			return nil, err
+		}
+	}
+
+	qr := QueryRequest{
+		Plugin:   plugin,
+		Endpoint: endpoint,
+		ChatID:   chatID,
+		Params:   params,
+		Stream:   true,
+	}
+
+	body, err := json.Marshal(qr)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: marshal request: %w", err)
+	}
+
+	httpReq, err := c.newRequest(ctx, "POST", "/v1/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/x-ndjson, application/json")
+
+	httpReq, finish := c.observe(httpReq, call)
+	res := &CallResult{}
+	start := time.Now()
+	done := func() {
+		finish(res)
+		c.logResult(ctx, call, res, time.Since(start))
+	}
+	c.log(ctx, slog.LevelDebug, "integrat request", append(callAttrs(call), slog.Bool("stream", true))...)
+
+	httpResp, err := c.doAuth(httpReq, call)
+	if err != nil {
+		res.Err = fmt.Errorf("integrat: http request: %w", err)
+		done()
+		return nil, res.Err
+	}
+	res.StatusCode = httpResp.StatusCode
+
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		respBody, err := io.ReadAll(httpResp.Body)
+		res.Bytes = int64(len(respBody))
+		if err != nil {
+			res.Err = fmt.Errorf("integrat: read response: %w", err)
+		} else {
+			res.Err = newAPIError(httpResp.StatusCode, httpResp.Header, respBody)
+		}
+		done()
+		return nil, res.Err
+	}
+
+	s := newStream(httpResp)
+	res.Cached, res.Stale = s.Cached, s.Stale
+	s.finish = func() {
+		res.Bytes = s.counter.n
+		res.Err = s.err
+		done()
+	}
+	return s, nil
+}
+
+// QueryStream выполняет запрос к потоковому эндпоинту и вызывает fn для каждого
+// элемента по мере декодирования. Чтобы остановиться досрочно, fn возвращает ErrStopStream.
+func (c *Client) QueryStream(ctx context.Context, plugin, endpoint string, chatID int64, params map[string]any, fn func(item json.RawMessage) error) error {
+	s, err := c.OpenStream(ctx, plugin, endpoint, chatID, params)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	for s.Next() {
+		if err := fn(s.Item()); err != nil {
+			if errors.Is(err, ErrStopStream) {
+				return nil
+			}
+			return err
+		}
+	}
+	return s.Err()
+}
+
+func newStream(resp *http.Response) *Stream {
+	ct := resp.Header.Get("Content-Type")
+	counter := &countingReader{r: resp.Body}
+	dec := json.NewDecoder(counter)
+	dec.UseNumber()
+	return &Stream{
+		Cached:  resp.Header.Get("X-Integrat-Cached") == "true",
+		Stale:   resp.Header.Get("X-Integrat-Stale") == "true",
+		body:    resp.Body,
+		counter: counter,
+		dec:     dec,
+		ndjson:  strings.Contains(ct, "ndjson") || strings.Contains(ct, "jsonl"),
+	}
+}
+
+// countingReader считает прочитанные байты (для CallResult.Bytes).
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// Next декодирует следующий элемент. Возвращает false по окончании потока
+// или при ошибке (см. Err).
+func (s *Stream) Next() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	if s.ndjson {
+		var raw json.RawMessage
+		if err := s.dec.Decode(&raw); err != nil {
+			if err != io.EOF {
+				s.err = fmt.Errorf("integrat: decode stream: %w", err)
+			}
+			s.done = true
+			return false
+		}
+		s.item = raw
+		return true
+	}
+
+	if !s.started {
+		s.started = true
+		if err := s.begin(); err != nil {
+			s.err = fmt.Errorf("integrat: decode stream: %w", err)
+			return false
+		}
+	}
+
+	if s.single != nil {
+		s.item, s.single = s.single, nil
+		s.done = true
+		return true
+	}
+
+	if !s.inArray || !s.dec.More() {
+		s.done = true
+		return false
+	}
+
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		s.err = fmt.Errorf("integrat: decode stream: %w", err)
+		return false
+	}
+	s.item = raw
+	return true
+}
+
+// Item возвращает текущий элемент (валиден до следующего вызова Next).
+func (s *Stream) Item() json.RawMessage { return s.item }
+
+// Err возвращает первую ошибку декодирования.
+func (s *Stream) Err() error { return s.err }
+
+// Close закрывает тело ответа. Повторный вызов безопасен.
+func (s *Stream) Close() error {
+	if s.finish != nil {
+		s.finish()
+		s.finish = nil
+	}
+	return s.body.Close()
+}
+
+// begin позиционирует декодер на первом элементе: верхнеуровневый массив
+// или массив в поле data. Нескалярное data без массива отдаётся одним элементом.
+func (s *Stream) begin() error {
+	tok, err := s.dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		s.inArray = true
+		return nil
+	case json.Delim('{'):
+		for s.dec.More() {
+			key, err := s.dec.Token()
+			if err != nil {
+				return err
+			}
+			first, err := s.dec.Token()
+			if err != nil {
+				return err
+			}
+			if key != "data" {
+				if _, err := readValue(s.dec, first); err != nil {
+					return err
+				}
+				continue
+			}
+			if first == json.Delim('[') {
+				s.inArray = true
+				return nil
+			}
+			raw, err := readValue(s.dec, first)
+			if err != nil {
+				return err
+			}
+			s.single = raw
+			return nil
+		}
+		return nil
+	default:
+		raw, err := readValue(s.dec, tok)
+		if err != nil {
+			return err
+		}
+		s.single = raw
+		return nil
+	}
+}
+
+// readValue дочитывает значение, начатое токеном first, и собирает его в JSON.
+func readValue(dec *json.Decoder, first json.Token) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := writeValue(&buf, dec, first); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeValue(buf *bytes.Buffer, dec *json.Decoder, tok json.Token) error {
+	switch tok {
+	case json.Delim('['):
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			next, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := writeValue(buf, dec, next); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // ]
+			return err
+		}
+		buf.WriteByte(']')
+		return nil
+	case json.Delim('{'):
+		buf.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			k, _ := json.Marshal(key)
+			buf.Write(k)
+			buf.WriteByte(':')
+			next, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := writeValue(buf, dec, next); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // }
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	default:
+		v, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		buf.Write(v)
+		return nil
+	}
+}
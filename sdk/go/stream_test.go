@@ -0,0 +1,164 @@
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func streamServer(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var qr QueryRequest
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &qr); err != nil {
+			t.Errorf("bad request body: %v", err)
+		}
+		if !qr.Stream {
+			t.Error("request stream = false, want true")
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Integrat-Cached", "true")
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func collect(t *testing.T, c *Client) []string {
+	t.Helper()
+	var items []string
+	err := c.QueryStream(context.Background(), "p", "e", 0, nil, func(item json.RawMessage) error {
+		items = append(items, string(item))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	return items
+}
+
+func TestQueryStream_Array(t *testing.T) {
+	srv := streamServer(t, "application/json", `[{"id":1},{"id":2},{"id":3}]`)
+	items := collect(t, NewWithURL("t", srv.URL))
+	if len(items) != 3 || items[2] != `{"id":3}` {
+		t.Errorf("items = %v", items)
+	}
+}
+
+func TestQueryStream_DataEnvelope(t *testing.T) {
+	srv := streamServer(t, "application/json", `{"ttl":5,"meta":{"a":[1,2]},"data":[{"id":1},{"id":2.50}]}`)
+	items := collect(t, NewWithURL("t", srv.URL))
+	if len(items) != 2 || items[1] != `{"id":2.50}` {
+		t.Errorf("items = %v", items)
+	}
+}
+
+func TestQueryStream_DataObject(t *testing.T) {
+	srv := streamServer(t, "application/json", `{"data":{"total":7,"tags":["a","b"]}}`)
+	items := collect(t, NewWithURL("t", srv.URL))
+	if len(items) != 1 || items[0] != `{"total":7,"tags":["a","b"]}` {
+		t.Errorf("items = %v", items)
+	}
+}
+
+func TestQueryStream_NDJSON(t *testing.T) {
+	srv := streamServer(t, "application/x-ndjson", "{\"id\":1}\n{\"id\":2}\n\n{\"id\":3}\n")
+	items := collect(t, NewWithURL("t", srv.URL))
+	if len(items) != 3 {
+		t.Errorf("items = %v", items)
+	}
+}
+
+func TestQueryStream_Stop(t *testing.T) {
+	srv := streamServer(t, "application/json", `[1,2,3,4]`)
+	c := NewWithURL("t", srv.URL)
+	n := 0
+	err := c.QueryStream(context.Background(), "p", "e", 0, nil, func(json.RawMessage) error {
+		n++
+		if n == 2 {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("callback calls = %d, want 2", n)
+	}
+}
+
+func TestQueryStream_DecodeError(t *testing.T) {
+	srv := streamServer(t, "application/json", `[{"id":1},{"id":`)
+	c := NewWithURL("t", srv.URL)
+	err := c.QueryStream(context.Background(), "p", "e", 0, nil, func(json.RawMessage) error { return nil })
+	if err == nil {
+		t.Fatal("expected decode error")
+	}
+}
+
+func TestOpenStream_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":"endpoint not found"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewWithURL("t", srv.URL).OpenStream(context.Background(), "p", "e", 0, nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestOpenStream_Headers(t *testing.T) {
+	srv := streamServer(t, "application/json", `[]`)
+	s, err := NewWithURL("t", srv.URL).OpenStream(context.Background(), "p", "e", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !s.Cached {
+		t.Error("Cached = false, want true")
+	}
+	if s.Next() {
+		t.Error("Next() = true on empty array")
+	}
+	if s.Err() != nil {
+		t.Errorf("Err() = %v", s.Err())
+	}
+}
+
+func TestOpenStream_OutlivesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"id\":1}\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		io.WriteString(w, "{\"id\":2}\n")
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	c.HTTPClient.Timeout = 50 * time.Millisecond
+	s, err := c.OpenStream(context.Background(), "p", "e", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	n := 0
+	for s.Next() {
+		n++
+	}
+	if n != 2 || s.Err() != nil {
+		t.Errorf("items = %d, err = %v; stream cut by HTTPClient.Timeout", n, s.Err())
+	}
+	if c.HTTPClient.Timeout != 50*time.Millisecond {
+		t.Errorf("HTTPClient.Timeout changed to %v", c.HTTPClient.Timeout)
+	}
+}
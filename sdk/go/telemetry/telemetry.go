@@ -0,0 +1,264 @@
+// Пакет telemetry — трейсинг и метрики вызовов Integrat SDK в стиле OpenTelemetry.
+//
+// Observer реализует integrat.Observer: на каждый вызов API создаёт спан,
+// пишет гистограммы длительности и размера ответа и пробрасывает
+// W3C traceparent в gateway. Данные уходят в Exporter — для тестов есть
+// InMemoryExporter, для продакшена достаточно адаптера к своему бэкенду.
+//
+//	exp := telemetry.NewInMemoryExporter()
+//	client.Observer = telemetry.NewObserver(exp)
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+// Имена метрик.
+const (
+	MetricDuration     = "integrat.client.duration"      // секунды
+	MetricResponseSize = "integrat.client.response.size" // байты
+)
+
+// TraceParentHeader — заголовок W3C Trace Context.
+const TraceParentHeader = "traceparent"
+
+// ── SpanContext / W3C traceparent ───────────────────────────────────────
+
+// SpanContext — идентификаторы спана по W3C Trace Context.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid возвращает true, если TraceID и SpanID ненулевые.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent форматирует значение заголовка traceparent.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceParent разбирает заголовок traceparent (версия 00).
+func ParseTraceParent(s string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext кладёт родительский спан в контекст.
+// Вызовы SDK с этим контекстом станут его дочерними спанами.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext достаёт спан из контекста.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// ── Данные ──────────────────────────────────────────────────────────────
+
+// Span — завершённый спан вызова API.
+type Span struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext // нулевой, если спан корневой
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]any
+	Err        error // nil — статус OK
+}
+
+// Measurement — одно значение гистограммы.
+type Measurement struct {
+	Name       string
+	Value      float64
+	Attributes map[string]any
+}
+
+// Exporter принимает спаны и значения гистограмм.
+type Exporter interface {
+	ExportSpan(Span)
+	RecordHistogram(Measurement)
+}
+
+// ── Observer ────────────────────────────────────────────────────────────
+
+// Observer — реализация integrat.Observer поверх Exporter.
+type Observer struct {
+	exp Exporter
+}
+
+var _ integrat.Observer = (*Observer)(nil)
+
+// NewObserver создаёт Observer, пишущий в exp.
+func NewObserver(exp Exporter) *Observer {
+	return &Observer{exp: exp}
+}
+
+type activeSpanKey struct{}
+
+type activeSpan struct {
+	name   string
+	sc     SpanContext
+	parent SpanContext
+	start  time.Time
+}
+
+// CallStart открывает спан и добавляет traceparent в заголовки запроса.
+func (o *Observer) CallStart(ctx context.Context, call *integrat.CallInfo) context.Context {
+	span := &activeSpan{name: spanName(call), start: time.Now()}
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		span.parent = parent
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+	} else {
+		rand.Read(span.sc.TraceID[:])
+		span.sc.Sampled = true
+	}
+	rand.Read(span.sc.SpanID[:])
+
+	if call.Header != nil {
+		call.Header.Set(TraceParentHeader, span.sc.TraceParent())
+	}
+	ctx = ContextWithSpanContext(ctx, span.sc)
+	return context.WithValue(ctx, activeSpanKey{}, span)
+}
+
+// CallEnd закрывает спан и записывает гистограммы.
+func (o *Observer) CallEnd(ctx context.Context, call *integrat.CallInfo, res *integrat.CallResult) {
+	span, ok := ctx.Value(activeSpanKey{}).(*activeSpan)
+	if !ok {
+		return
+	}
+
+	attrs := callAttributes(call, res)
+	o.exp.ExportSpan(Span{
+		Name:       span.name,
+		Context:    span.sc,
+		Parent:     span.parent,
+		Start:      span.start,
+		End:        span.start.Add(res.Duration),
+		Attributes: attrs,
+		Err:        res.Err,
+	})
+	o.exp.RecordHistogram(Measurement{Name: MetricDuration, Value: res.Duration.Seconds(), Attributes: attrs})
+	o.exp.RecordHistogram(Measurement{Name: MetricResponseSize, Value: float64(res.Bytes), Attributes: attrs})
+}
+
+func spanName(call *integrat.CallInfo) string {
+	if call.Plugin != "" {
+		return "integrat.query " + call.Plugin + "/" + call.Endpoint
+	}
+	return "integrat " + call.Method + " " + call.Path
+}
+
+func callAttributes(call *integrat.CallInfo, res *integrat.CallResult) map[string]any {
+	attrs := map[string]any{
+		"http.request.method":       call.Method,
+		"url.path":                  call.Path,
+		"http.response.status_code": res.StatusCode,
+		"integrat.cached":           res.Cached,
+		"integrat.stale":            res.Stale,
+	}
+	if call.Plugin != "" {
+		attrs["integrat.plugin"] = call.Plugin
+		attrs["integrat.endpoint"] = call.Endpoint
+	}
+	if call.Stream {
+		attrs["integrat.stream"] = true
+	}
+	if res.Sentinel != nil {
+		attrs["error.type"] = res.Sentinel.Error()
+	} else if res.Err != nil {
+		attrs["error.type"] = "other"
+	}
+	return attrs
+}
+
+// ── In-memory exporter ──────────────────────────────────────────────────
+
+// InMemoryExporter хранит спаны и измерения в памяти (для тестов).
+type InMemoryExporter struct {
+	mu           sync.Mutex
+	spans        []Span
+	measurements []Measurement
+}
+
+// NewInMemoryExporter создаёт пустой InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan сохраняет спан.
+func (e *InMemoryExporter) ExportSpan(s Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// RecordHistogram сохраняет измерение.
+func (e *InMemoryExporter) RecordHistogram(m Measurement) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.measurements = append(e.measurements, m)
+}
+
+// Spans возвращает копию сохранённых спанов.
+func (e *InMemoryExporter) Spans() []Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Span(nil), e.spans...)
+}
+
+// Histogram возвращает значения гистограммы name.
+func (e *InMemoryExporter) Histogram(name string) []float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var vals []float64
+	for _, m := range e.measurements {
+		if m.Name == name {
+			vals = append(vals, m.Value)
+		}
+	}
+	return vals
+}
+
+// Reset очищает сохранённые данные.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+	e.measurements = nil
+}
@@ -0,0 +1,150 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+func TestTraceParent_RoundTrip(t *testing.T) {
+	in := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceParent(in)
+	if !ok {
+		t.Fatal("ParseTraceParent failed")
+	}
+	if !sc.Sampled {
+		t.Error("Sampled = false")
+	}
+	if got := sc.TraceParent(); got != in {
+		t.Errorf("TraceParent() = %q, want %q", got, in)
+	}
+
+	for _, bad := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceParent(bad); ok {
+			t.Errorf("ParseTraceParent(%q) = ok", bad)
+		}
+	}
+}
+
+func TestObserver_QuerySpanAndMetrics(t *testing.T) {
+	var gotTP string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTP = r.Header.Get(TraceParentHeader)
+		w.Header().Set("X-Integrat-Cached", "true")
+		io.WriteString(w, `{"data":{"ok":true},"ttl":30}`)
+	}))
+	defer srv.Close()
+
+	exp := NewInMemoryExporter()
+	c := integrat.NewWithURL("t", srv.URL)
+	c.Observer = NewObserver(exp)
+
+	if _, err := c.Query("channel-mcp", "tags.top", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exp.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	s := spans[0]
+	if s.Name != "integrat.query channel-mcp/tags.top" {
+		t.Errorf("span name = %q", s.Name)
+	}
+	if s.Attributes["integrat.cached"] != true || s.Attributes["http.response.status_code"] != 200 {
+		t.Errorf("attributes = %v", s.Attributes)
+	}
+	if s.Parent.IsValid() {
+		t.Error("root span has parent")
+	}
+
+	sc, ok := ParseTraceParent(gotTP)
+	if !ok || sc != s.Context {
+		t.Errorf("traceparent %q does not match span %s", gotTP, s.Context.TraceParent())
+	}
+
+	if sizes := exp.Histogram(MetricResponseSize); len(sizes) != 1 || sizes[0] == 0 {
+		t.Errorf("response size histogram = %v", sizes)
+	}
+	if durs := exp.Histogram(MetricDuration); len(durs) != 1 {
+		t.Errorf("duration histogram = %v", durs)
+	}
+}
+
+func TestObserver_ErrorSentinelAndParent(t *testing.T) {
+	var gotTP string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTP = r.Header.Get(TraceParentHeader)
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, `{"error":"provider offline"}`)
+	}))
+	defer srv.Close()
+
+	exp := NewInMemoryExporter()
+	c := integrat.NewWithURL("t", srv.URL)
+	c.Observer = NewObserver(exp)
+
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithSpanContext(context.Background(), parent)
+	if _, err := c.QueryContext(ctx, "p", "e", nil); !errors.Is(err, integrat.ErrProvider) {
+		t.Fatalf("err = %v", err)
+	}
+
+	spans := exp.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	s := spans[0]
+	if s.Parent != parent || s.Context.TraceID != parent.TraceID || s.Context.SpanID == parent.SpanID {
+		t.Errorf("child span not linked to parent: %+v", s)
+	}
+	if sc, ok := ParseTraceParent(gotTP); !ok || sc != s.Context {
+		t.Errorf("traceparent %q does not match span %s", gotTP, s.Context.TraceParent())
+	}
+	if s.Attributes["error.type"] != integrat.ErrProvider.Error() {
+		t.Errorf("error.type = %v", s.Attributes["error.type"])
+	}
+}
+
+func TestObserver_StreamEndsOnClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"id\":1}\n{\"id\":2}\n")
+	}))
+	defer srv.Close()
+
+	exp := NewInMemoryExporter()
+	c := integrat.NewWithURL("t", srv.URL)
+	c.Observer = NewObserver(exp)
+
+	s, err := c.OpenStream(context.Background(), "p", "e", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s.Next() {
+	}
+	if len(exp.Spans()) != 0 {
+		t.Error("span exported before Close")
+	}
+	s.Close()
+	s.Close()
+
+	spans := exp.Spans()
+	if len(spans) != 1 || spans[0].Attributes["integrat.stream"] != true {
+		t.Fatalf("spans = %+v", spans)
+	}
+	if sizes := exp.Histogram(MetricResponseSize); sizes[0] != 18 {
+		t.Errorf("stream bytes = %v, want 18", sizes)
+	}
+}
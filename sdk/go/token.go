@@ -0,0 +1,259 @@
+// Источники API-токена для клиента.
+// Клиент запрашивает токен перед каждым запросом, а при ответе 401
+// сбрасывает его у источника (TokenInvalidator) и повторяет запрос один раз —
+// так долгоживущие сервисы переживают ротацию токена без рестарта.
+package integrat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource выдаёт актуальный API-токен перед каждым запросом.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator — источник, которому можно сообщить, что токен отвергнут
+// API (401). Следующий вызов Token должен вернуть свежий токен.
+type TokenInvalidator interface {
+	Invalidate(rejected string)
+}
+
+// ErrNoToken — источник не смог выдать токен.
+var ErrNoToken = errors.New("integrat: no token")
+
+// ── Static / Env ────────────────────────────────────────────────────────
+
+// StaticToken — неизменный токен.
+type StaticToken string
+
+// Token возвращает сам токен.
+func (t StaticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+// EnvVarToken читает токен из переменной окружения при каждом запросе.
+type EnvVarToken string
+
+// Token возвращает значение переменной окружения.
+func (e EnvVarToken) Token(context.Context) (string, error) {
+	v := strings.TrimSpace(os.Getenv(string(e)))
+	if v == "" {
+		return "", fmt.Errorf("%w: env %s is empty", ErrNoToken, string(e))
+	}
+	return v, nil
+}
+
+// ── File ────────────────────────────────────────────────────────────────
+
+// FileToken читает токен из файла и перечитывает его при изменении
+// (mtime/размер) или после 401. Подходит для секретов, которые
+// монтирует и ротирует оркестратор.
+type FileToken struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileToken создаёт источник токена из файла path.
+func NewFileToken(path string) *FileToken {
+	return &FileToken{path: path}
+}
+
+// Token возвращает содержимое файла без пробельных символов по краям.
+func (f *FileToken) Token(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoToken, err)
+	}
+	if f.token != "" && st.ModTime().Equal(f.modTime) && st.Size() == f.size {
+		return f.token, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoToken, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: file %s is empty", ErrNoToken, f.path)
+	}
+	f.token, f.modTime, f.size = token, st.ModTime(), st.Size()
+	return token, nil
+}
+
+// Invalidate заставляет перечитать файл при следующем запросе.
+func (f *FileToken) Invalidate(rejected string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token == rejected {
+		f.token = ""
+	}
+}
+
+// ── Обмен учётных данных через /v1/auth/token ───────────────────────────
+
+// Credentials — учётные данные для POST /v1/auth/token.
+type Credentials struct {
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	InitData     string `json:"init_data,omitempty"` // Telegram WebApp initData
+}
+
+// AuthToken — ответ POST /v1/auth/token.
+type AuthToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// ExchangeToken обменивает учётные данные на API-токен и кеширует его
+// до истечения (с запасом RefreshBefore) или до 401 от API.
+type ExchangeToken struct {
+	BaseURL       string
+	Credentials   Credentials
+	HTTPClient    *http.Client
+	RefreshBefore time.Duration // обновлять заранее до expires_at (по умолчанию 30s)
+
+	mu    sync.Mutex
+	token AuthToken
+	now   func() time.Time
+}
+
+// NewExchangeToken создаёт источник, получающий токен на baseURL/v1/auth/token.
+func NewExchangeToken(baseURL string, creds Credentials) *ExchangeToken {
+	return &ExchangeToken{
+		BaseURL:     baseURL,
+		Credentials: creds,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Token возвращает закешированный токен или получает новый.
+func (e *ExchangeToken) Token(ctx context.Context) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token.Token != "" && !e.expiring() {
+		return e.token.Token, nil
+	}
+	tok, err := e.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	e.token = *tok
+	return tok.Token, nil
+}
+
+// Invalidate сбрасывает отвергнутый токен.
+func (e *ExchangeToken) Invalidate(rejected string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token.Token == rejected {
+		e.token = AuthToken{}
+	}
+}
+
+func (e *ExchangeToken) expiring() bool {
+	if e.token.ExpiresAt.IsZero() {
+		return false
+	}
+	now := time.Now
+	if e.now != nil {
+		now = e.now
+	}
+	before := e.RefreshBefore
+	if before <= 0 {
+		before = 30 * time.Second
+	}
+	return !now().Add(before).Before(e.token.ExpiresAt)
+}
+
+func (e *ExchangeToken) exchange(ctx context.Context) (*AuthToken, error) {
+	body, err := json.Marshal(e.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: marshal credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", e.BaseURL+"/v1/auth/token", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("integrat: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	hc := e.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("integrat: read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, resp.Header, respBody)
+	}
+
+	var tok AuthToken
+	if err := json.Unmarshal(respBody, &tok); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	if tok.Token == "" {
+		return nil, fmt.Errorf("%w: empty token in /v1/auth/token response", ErrNoToken)
+	}
+	return &tok, nil
+}
+
+// ── Обновление токена по 401 ────────────────────────────────────────────
+
+// doAuth отправляет запрос через doRetry; при 401 сбрасывает токен у
+// источника и, если получен другой токен, повторяет запрос один раз.
+func (c *Client) doAuth(req *http.Request, call *CallInfo) (*http.Response, error) {
+	resp, err := c.doRetry(req, call)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	inv, ok := c.TokenSource.(TokenInvalidator)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	rejected := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	inv.Invalidate(rejected)
+	token, terr := c.token(req.Context())
+	if terr != nil || token == rejected {
+		c.log(req.Context(), slog.LevelDebug, "integrat token refresh failed", callAttrs(call)...)
+		return resp, nil
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("integrat: retry: %w", err)
+		}
+		req.Body = body
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	c.log(req.Context(), slog.LevelDebug, "integrat token refreshed", callAttrs(call)...)
+	return c.doRetry(req, call)
+}
@@ -0,0 +1,157 @@
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvToken(t *testing.T) {
+	t.Setenv("MY_ITG_TOKEN", " itg_env \n")
+	tok, err := EnvVarToken("MY_ITG_TOKEN").Token(context.Background())
+	if err != nil || tok != "itg_env" {
+		t.Errorf("Token() = %q, %v", tok, err)
+	}
+
+	t.Setenv("MY_ITG_TOKEN", "")
+	if _, err := EnvVarToken("MY_ITG_TOKEN").Token(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Errorf("err = %v, want ErrNoToken", err)
+	}
+}
+
+func TestFileToken_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("itg_one\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ft := NewFileToken(path)
+	if tok, _ := ft.Token(context.Background()); tok != "itg_one" {
+		t.Fatalf("Token() = %q", tok)
+	}
+
+	os.WriteFile(path, []byte("itg_second"), 0o600)
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(path, future, future)
+	if tok, _ := ft.Token(context.Background()); tok != "itg_second" {
+		t.Errorf("Token() after rotation = %q", tok)
+	}
+}
+
+// authServer принимает только токен valid и выдаёт токены по /v1/auth/token.
+func authServer(t *testing.T, issued *atomic.Int32, valid *atomic.Value) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/token" {
+			var creds Credentials
+			json.NewDecoder(r.Body).Decode(&creds)
+			if creds.ClientSecret != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			n := issued.Add(1)
+			tok := "itg_" + string(rune('a'+n))
+			if n == 1 {
+				tok = "itg_stale" // первый токен к моменту запроса уже отозван
+			}
+			json.NewEncoder(w).Encode(AuthToken{Token: tok})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"token revoked"}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			t.Error("retried request has empty body")
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExchangeToken_RefreshOnUnauthorized(t *testing.T) {
+	var issued atomic.Int32
+	var valid atomic.Value
+	valid.Store("itg_c")
+	srv := authServer(t, &issued, &valid)
+
+	c := NewClient(WithBaseURL(srv.URL), WithCredentials(Credentials{ClientID: "ci", ClientSecret: "s3cret"}))
+	if _, err := c.Query("p", "e", map[string]any{"x": 1}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if issued.Load() != 2 {
+		t.Errorf("issued = %d, want 2 (initial + refresh after 401)", issued.Load())
+	}
+
+	// Токен закеширован — повторный запрос без обмена.
+	if _, err := c.Query("p", "e", nil); err != nil {
+		t.Fatal(err)
+	}
+	if issued.Load() != 2 {
+		t.Errorf("issued = %d, want 2 (cached token)", issued.Load())
+	}
+}
+
+func TestExchangeToken_BadCredentials(t *testing.T) {
+	var issued atomic.Int32
+	var valid atomic.Value
+	valid.Store("x")
+	srv := authServer(t, &issued, &valid)
+
+	c := NewClient(WithBaseURL(srv.URL), WithCredentials(Credentials{ClientSecret: "wrong"}))
+	if _, err := c.ListPlugins(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestExchangeToken_Expiry(t *testing.T) {
+	var issued atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		json.NewEncoder(w).Encode(AuthToken{Token: "itg_x", ExpiresAt: time.Unix(1000, 0)})
+	}))
+	defer srv.Close()
+
+	ex := NewExchangeToken(srv.URL, Credentials{})
+	now := time.Unix(900, 0)
+	ex.now = func() time.Time { return now }
+
+	ex.Token(context.Background())
+	ex.Token(context.Background())
+	if issued.Load() != 1 {
+		t.Errorf("issued = %d, want 1", issued.Load())
+	}
+	now = time.Unix(980, 0) // в пределах RefreshBefore
+	ex.Token(context.Background())
+	if issued.Load() != 2 {
+		t.Errorf("issued = %d, want 2 (refresh before expiry)", issued.Load())
+	}
+}
+
+func TestStaticToken_NoRefresh(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithTokenSource(StaticToken("itg_x")))
+	if _, err := c.ListPlugins(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("err = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
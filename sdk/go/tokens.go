@@ -0,0 +1,91 @@
+// Управление API-токенами itg_: выпуск с ограниченными скоупами и сроком
+// действия, список и отзыв. Позволяет выдавать CI-задачам узкие токены
+// вместо личного.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Скоупы API-токенов.
+const (
+	ScopeQuery           = "query"            // только запросы данных (/v1/query)
+	ScopePublisher       = "publisher"        // управление своими плагинами и эндпоинтами
+	ScopeMarketplaceRead = "marketplace:read" // чтение маркетплейса
+)
+
+var validScopes = map[string]bool{
+	ScopeQuery: true, ScopePublisher: true, ScopeMarketplaceRead: true,
+}
+
+// APIToken — выпущенный API-токен.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`          // первые символы токена для опознания (itg_ab12)
+	Token      string     `json:"token,omitempty"` // полный токен — только в ответе CreateToken
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  string     `json:"created_at"`
+}
+
+// Expired сообщает, истёк ли токен на момент now.
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// CreateTokenParams — параметры выпуска токена.
+type CreateTokenParams struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil — бессрочный
+}
+
+// CreateToken выпускает API-токен. Полный токен возвращается только здесь.
+func (c *Client) CreateToken(params CreateTokenParams) (*APIToken, error) {
+	if params.Name == "" {
+		return nil, fmt.Errorf("integrat: token name is required")
+	}
+	if len(params.Scopes) == 0 {
+		return nil, fmt.Errorf("integrat: at least one token scope is required")
+	}
+	for _, s := range params.Scopes {
+		if !validScopes[s] {
+			return nil, fmt.Errorf("integrat: unknown token scope %q (valid: %s, %s, %s)",
+				s, ScopeQuery, ScopePublisher, ScopeMarketplaceRead)
+		}
+	}
+
+	respBody, _, err := c.doJSON(context.Background(), "POST", "/v1/tokens", params)
+	if err != nil {
+		return nil, err
+	}
+	var token APIToken
+	if err := json.Unmarshal(respBody, &token); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &token, nil
+}
+
+// ListTokens возвращает токены текущего пользователя (без секретной части).
+func (c *Client) ListTokens() ([]APIToken, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", "/v1/tokens", nil)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []APIToken
+	if err := json.Unmarshal(respBody, &tokens); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken отзывает токен.
+func (c *Client) RevokeToken(id int64) error {
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/tokens/%d", id), nil)
+	return err
+}
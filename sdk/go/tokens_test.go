@@ -0,0 +1,83 @@
+package integrat
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateToken(t *testing.T) {
+	var got CreateTokenParams
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/tokens" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"id":5,"name":"ci","prefix":"itg_ab12","token":"itg_ab12xyz","scopes":["query"],"expires_at":"2026-12-01T00:00:00Z"}`)
+	}))
+	defer srv.Close()
+
+	exp := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	c := NewWithURL("t", srv.URL)
+	tok, err := c.CreateToken(CreateTokenParams{Name: "ci", Scopes: []string{ScopeQuery}, ExpiresAt: &exp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ci" || got.ExpiresAt == nil || !got.ExpiresAt.Equal(exp) {
+		t.Errorf("request = %+v", got)
+	}
+	if tok.ID != 5 || tok.Token != "itg_ab12xyz" {
+		t.Errorf("token = %+v", tok)
+	}
+	if tok.Expired(exp.Add(-time.Hour)) || !tok.Expired(exp) {
+		t.Error("Expired() mismatch")
+	}
+}
+
+func TestCreateToken_Validation(t *testing.T) {
+	c := NewWithURL("t", "http://127.0.0.1:0")
+	tests := []struct {
+		params CreateTokenParams
+		want   string
+	}{
+		{CreateTokenParams{Scopes: []string{ScopeQuery}}, "name"},
+		{CreateTokenParams{Name: "x"}, "scope"},
+		{CreateTokenParams{Name: "x", Scopes: []string{"admin"}}, `"admin"`},
+	}
+	for _, tt := range tests {
+		_, err := c.CreateToken(tt.params)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("CreateToken(%+v) err = %v, want %q", tt.params, err, tt.want)
+		}
+	}
+}
+
+func TestListAndRevokeTokens(t *testing.T) {
+	var revoked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			io.WriteString(w, `[{"id":1,"name":"a","prefix":"itg_1","scopes":["publisher"]}]`)
+		case "DELETE":
+			revoked = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	tokens, err := c.ListTokens()
+	if err != nil || len(tokens) != 1 || tokens[0].Scopes[0] != ScopePublisher {
+		t.Fatalf("ListTokens = %+v, %v", tokens, err)
+	}
+	if err := c.RevokeToken(1); err != nil {
+		t.Fatal(err)
+	}
+	if revoked != "/v1/tokens/1" {
+		t.Errorf("revoked path = %q", revoked)
+	}
+}
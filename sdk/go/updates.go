@@ -0,0 +1,288 @@
+// Подписка на обновления эндпоинтов (subscribe: true) через Server-Sent
+// Events. Соединение восстанавливается автоматически, пропущенные события
+// gateway досылает по заголовку Last-Event-ID.
+package integrat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNotEventStream — ответ подписки не в формате SSE; переподключение
+// не поможет.
+var errNotEventStream = errors.New("integrat: subscribe: response is not text/event-stream")
+
+// Update — событие подписки.
+type Update struct {
+	ID    string          // id события; с него продолжится поток после переподключения
+	Event string          // тип события, по умолчанию "message"
+	Data  json.RawMessage // данные события (JSON)
+}
+
+// Updates — итератор по событиям подписки.
+//
+//	u, err := client.SubscribeUpdates(ctx, "channel-mcp", "messages.new", params)
+//	if err != nil { ... }
+//	defer u.Close()
+//	for u.Next() {
+//	    var msg Message
+//	    _ = json.Unmarshal(u.Update().Data, &msg)
+//	}
+//	if err := u.Err(); err != nil { ... }
+//
+// Next блокируется до следующего события. Чтобы прервать ожидание из другой
+// горутины, отмените ctx — Close нельзя вызывать одновременно с Next.
+type Updates struct {
+	c    *Client
+	ctx  context.Context
+	call *CallInfo
+	body []byte
+
+	resp    *http.Response
+	rd      *bufio.Reader
+	finish  func(error)
+	lastID  string
+	retry   time.Duration // пауза из поля retry: сервера
+	attempt int
+	cur     Update
+	ended   bool
+	err     error
+}
+
+// SubscribeUpdates открывает подписку на обновления эндпоинта в чате
+// DefaultChatID. Ошибка первого подключения возвращается сразу; обрывы
+// после него переподключаются с паузой (поле retry: сервера, не меньше
+// экспоненты Client.Retry) до отмены ctx. Ответы 429 и 5xx при
+// переподключении тоже повторяются, остальные ошибки завершают подписку.
+// HTTPClient.Timeout на соединение не действует — подписку завершает ctx.
+func (c *Client) SubscribeUpdates(ctx context.Context, plugin, endpoint string, params map[string]any) (*Updates, error) {
+	call := &CallInfo{Method: "POST", Path: "/v1/subscribe", Plugin: plugin, Endpoint: endpoint, Stream: true}
+	if c.ValidateParams {
+		var err error
+		if params, err = c.checkParams(ctx, call, params); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := json.Marshal(QueryRequest{
+		Plugin:   plugin,
+		Endpoint: endpoint,
+		ChatID:   c.DefaultChatID,
+		Params:   params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("integrat: marshal request: %w", err)
+	}
+
+	u := &Updates{c: c, ctx: ctx, call: call, body: body}
+	if err := u.connect(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// connect открывает SSE-соединение, продолжая с lastID.
+func (u *Updates) connect() error {
+	c := u.c
+	httpReq, err := c.newRequest(u.ctx, "POST", "/v1/subscribe", bytes.NewReader(u.body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+	if u.lastID != "" {
+		httpReq.Header.Set("Last-Event-ID", u.lastID)
+	}
+
+	httpReq, finish := c.observe(httpReq, u.call)
+	res := &CallResult{}
+	start := time.Now()
+	done := func(err error) {
+		res.Err = err
+		finish(res)
+		c.logResult(u.ctx, u.call, res, time.Since(start))
+	}
+	c.log(u.ctx, slog.LevelDebug, "integrat subscribe", append(callAttrs(u.call), slog.String("last_event_id", u.lastID))...)
+
+	httpResp, err := c.doAuth(httpReq, u.call)
+	if err != nil {
+		err = fmt.Errorf("integrat: http request: %w", err)
+		done(err)
+		return err
+	}
+	res.StatusCode = httpResp.StatusCode
+
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		respBody, err := io.ReadAll(httpResp.Body)
+		res.Bytes = int64(len(respBody))
+		if err != nil {
+			err = fmt.Errorf("integrat: read response: %w", err)
+		} else {
+			err = newAPIError(httpResp.StatusCode, httpResp.Header, respBody)
+		}
+		done(err)
+		return err
+	}
+	// 204 — сервер просит больше не переподключаться.
+	if httpResp.StatusCode == http.StatusNoContent {
+		httpResp.Body.Close()
+		done(nil)
+		u.ended = true
+		return nil
+	}
+	if ct := httpResp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		httpResp.Body.Close()
+		err := fmt.Errorf("%w (Content-Type %q)", errNotEventStream, ct)
+		done(err)
+		return err
+	}
+
+	counter := &countingReader{r: httpResp.Body}
+	u.resp = httpResp
+	u.rd = bufio.NewReader(counter)
+	u.finish = func(err error) {
+		res.Bytes = counter.n
+		done(err)
+	}
+	return nil
+}
+
+// disconnect закрывает текущее соединение.
+func (u *Updates) disconnect(err error) {
+	if u.resp == nil {
+		return
+	}
+	u.resp.Body.Close()
+	u.finish(err)
+	u.resp, u.rd, u.finish = nil, nil, nil
+}
+
+// Next ждёт следующее событие. Возвращает false при отмене ctx, при
+// завершении подписки сервером (204) или неустранимой ошибке (см. Err).
+func (u *Updates) Next() bool {
+	for u.err == nil && !u.ended {
+		if u.rd != nil {
+			ev, err := u.readEvent()
+			if err == nil {
+				u.cur = ev
+				u.attempt = 0
+				return true
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			u.disconnect(err)
+		}
+		if err := u.ctx.Err(); err != nil {
+			u.err = err
+			return false
+		}
+
+		u.attempt++
+		wait := u.c.Retry.backoff(u.attempt)
+		if u.retry > wait {
+			wait = u.retry
+		}
+		if err := sleepCtx(u.ctx, wait); err != nil {
+			u.err = err
+			return false
+		}
+		if err := u.connect(); err != nil && !reconnectable(err) {
+			u.err = err
+		}
+	}
+	return false
+}
+
+// reconnectable сообщает, стоит ли переподключаться после ошибки.
+func reconnectable(err error) bool {
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.StatusCode == http.StatusTooManyRequests || ae.StatusCode >= 500
+	}
+	return !errors.Is(err, errNotEventStream)
+}
+
+// readEvent читает поля до пустой строки и собирает событие. Блоки без
+// data (комментарии-keepalive, одиночные id/retry) не возвращаются.
+func (u *Updates) readEvent() (Update, error) {
+	var ev Update
+	var data []byte
+	hasData := false
+	id := u.lastID
+	for {
+		line, err := u.rd.ReadString('\n')
+		if err != nil {
+			// Незавершённое событие отбрасывается.
+			return Update{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			// id фиксируется только по завершении блока: с незавершённого
+			// события поток продолжится заново.
+			u.lastID = id
+			if !hasData {
+				ev = Update{}
+				continue
+			}
+			if ev.Event == "" {
+				ev.Event = "message"
+			}
+			ev.ID = u.lastID
+			ev.Data = data
+			return ev, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Event = value
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				id = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				u.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// Update возвращает текущее событие.
+func (u *Updates) Update() Update { return u.cur }
+
+// LastEventID возвращает id последнего полученного события.
+func (u *Updates) LastEventID() string { return u.lastID }
+
+// Err возвращает ошибку, остановившую подписку (в т.ч. ctx.Err()).
+func (u *Updates) Err() error { return u.err }
+
+// Close закрывает соединение и завершает подписку.
+func (u *Updates) Close() error {
+	u.disconnect(nil)
+	u.ended = true
+	return nil
+}
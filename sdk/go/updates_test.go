@@ -0,0 +1,189 @@
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeUpdates_Resume(t *testing.T) {
+	var mu sync.Mutex
+	var lastIDs []string
+	var posted QueryRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/subscribe" || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("%s %s Accept=%q", r.Method, r.URL.Path, r.Header.Get("Accept"))
+		}
+		json.NewDecoder(r.Body).Decode(&posted)
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		n := len(lastIDs)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch n {
+		case 1:
+			// Первое соединение обрывается посреди события 3.
+			io.WriteString(w, "retry: 10\n\n: keepalive\n\nid: 1\ndata: {\"n\":1}\n\nid: 2\nevent: edit\ndata: {\"n\":2}\n\nid: 3\ndata: {\"n\"")
+		case 2:
+			io.WriteString(w, "id: 3\r\ndata: {\"n\":3,\r\ndata: \"multi\":true}\r\n\r\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	c.DefaultChatID = -100
+	c.Retry = RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	u, err := c.SubscribeUpdates(context.Background(), "channel-mcp", "messages.new", map[string]any{"channel": "durov"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+
+	var got []Update
+	for u.Next() {
+		got = append(got, u.Update())
+	}
+	if err := u.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("updates = %+v", got)
+	}
+	if got[0].ID != "1" || got[0].Event != "message" || string(got[0].Data) != `{"n":1}` {
+		t.Errorf("update 1 = %+v", got[0])
+	}
+	if got[1].Event != "edit" {
+		t.Errorf("update 2 = %+v", got[1])
+	}
+	if got[2].ID != "3" || string(got[2].Data) != "{\"n\":3,\n\"multi\":true}" {
+		t.Errorf("update 3 = %+v", got[2])
+	}
+	// Событие 3 оборвано — поток продолжается с 2.
+	if got := strings.Join(lastIDs, ","); got != ",2,3" {
+		t.Errorf("Last-Event-ID = %q", lastIDs)
+	}
+	if posted.ChatID != -100 || posted.Params["channel"] != "durov" {
+		t.Errorf("posted = %+v", posted)
+	}
+}
+
+func TestSubscribeUpdates_Errors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "id: 1\ndata: {}\n\n")
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"error":"access revoked"}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	c.Retry = RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	u, err := c.SubscribeUpdates(context.Background(), "p", "e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+
+	n := 0
+	for u.Next() {
+		n++
+	}
+	if n != 1 || !errors.Is(u.Err(), ErrForbidden) {
+		t.Errorf("updates = %d, err = %v; want 1 update then ErrForbidden after retrying 503", n, u.Err())
+	}
+}
+
+func TestSubscribeUpdates_NotEventStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":[]}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewWithURL("t", srv.URL).SubscribeUpdates(context.Background(), "p", "e", nil)
+	if !errors.Is(err, errNotEventStream) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSubscribeUpdates_Cancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	u, err := NewWithURL("t", srv.URL).SubscribeUpdates(ctx, "p", "e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if u.Next() {
+		t.Fatal("unexpected update")
+	}
+	if !errors.Is(u.Err(), context.Canceled) {
+		t.Errorf("err = %v", u.Err())
+	}
+}
+
+func TestSubscribeUpdates_OutlivesClientTimeout(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			io.WriteString(w, "data: {}\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(60 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	c.HTTPClient.Timeout = 50 * time.Millisecond
+	c.Retry = RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	u, err := c.SubscribeUpdates(ctx, "p", "e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+
+	for i := 0; i < 3; i++ {
+		if !u.Next() {
+			t.Fatalf("update %d: err = %v", i+1, u.Err())
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("connections = %d, want 1: stream cut by HTTPClient.Timeout", conns)
+	}
+}
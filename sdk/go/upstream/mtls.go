@@ -0,0 +1,62 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ── mTLS ────────────────────────────────────────────────────────────────
+
+// TLSConfig собирает tls.Config с клиентским сертификатом (auth.type: mtls).
+// certPEM и keyPEM — содержимое переменных auth.cert_env/auth.key_env;
+// caPEM — сертификаты CA провайдера, nil — системные.
+func TLSConfig(certPEM, keyPEM, caPEM []byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("upstream: client certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("upstream: ca: no certificates found in PEM")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// LoadTLSConfig — то же, что TLSConfig, для auth.cert_file/key_file/ca_file.
+// caFile может быть пустым.
+func LoadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("upstream: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("upstream: %w", err)
+	}
+	var caPEM []byte
+	if caFile != "" {
+		if caPEM, err = os.ReadFile(caFile); err != nil {
+			return nil, fmt.Errorf("upstream: %w", err)
+		}
+	}
+	return TLSConfig(certPEM, keyPEM, caPEM)
+}
+
+// NewMTLSTransport возвращает копию http.DefaultTransport с заданным
+// tls.Config.
+func NewMTLSTransport(cfg *tls.Config) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = cfg
+	return tr
+}
@@ -0,0 +1,136 @@
+package upstream
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI — CA и подписанный им клиентский сертификат.
+type testPKI struct {
+	caPool  *x509.CertPool
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "integrat gateway"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return testPKI{
+		caPool:  pool,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// mtlsServer — стенд провайдера, требующий клиентский сертификат.
+func mtlsServer(t *testing.T, pki testPKI) (*httptest.Server, []byte) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pki.caPool}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return srv, serverCA
+}
+
+func TestMTLS_ClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	srv, serverCA := mtlsServer(t, pki)
+
+	cfg, err := TLSConfig(pki.certPEM, pki.keyPEM, serverCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: NewMTLSTransport(cfg)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); string(b) != "integrat gateway" {
+		t.Errorf("peer CN = %q", b)
+	}
+}
+
+func TestMTLS_WithoutCertificateRejected(t *testing.T) {
+	pki := newTestPKI(t)
+	srv, serverCA := mtlsServer(t, pki)
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(serverCA)
+	client := &http.Client{Transport: NewMTLSTransport(&tls.Config{RootCAs: pool})}
+	if resp, err := client.Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("request without client certificate succeeded")
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pki.certPEM, 0o600)
+	os.WriteFile(keyFile, pki.keyPEM, 0o600)
+
+	cfg, err := LoadTLSConfig(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Certificates) != 1 || cfg.RootCAs != nil {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if _, err := LoadTLSConfig(certFile, filepath.Join(dir, "missing.key"), ""); err == nil {
+		t.Error("expected error for missing key file")
+	}
+	if _, err := TLSConfig(pki.certPEM, pki.keyPEM, []byte("not pem")); err == nil {
+		t.Error("expected error for invalid CA")
+	}
+}
@@ -0,0 +1,177 @@
+// Пакет upstream — транспорты для запросов gateway к провайдеру по
+// provider.auth из integrat.yaml: OAuth2 client credentials и mTLS.
+//
+//	rt := &upstream.ClientCredentials{
+//		TokenURL:     "https://auth.example.com/oauth/token", // auth.token_url
+//		ClientID:     os.Getenv("EXAMPLE_CLIENT_ID"),         // auth.client_id_env
+//		ClientSecret: os.Getenv("EXAMPLE_CLIENT_SECRET"),     // auth.client_secret_env
+//		Scopes:       []string{"read:posts"},                 // auth.scopes
+//	}
+//	client := &http.Client{Transport: rt}
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── OAuth2 client credentials ───────────────────────────────────────────
+
+// expiryDelta — токен обновляется заранее, чтобы не уйти к провайдеру
+// с истекающим.
+const expiryDelta = 30 * time.Second
+
+// ClientCredentials — http.RoundTripper, который получает access token по
+// OAuth2 client credentials (RFC 6749, 4.4), кеширует его до истечения
+// и добавляет в запросы как Bearer. Если провайдер ответил 401, токен
+// сбрасывается и запрос повторяется один раз (когда тело можно перечитать).
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Base         http.RoundTripper // транспорт к провайдеру; nil — http.DefaultTransport
+	TokenClient  *http.Client      // клиент для TokenURL; nil — http.DefaultClient
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time // нулевое — без срока
+	now    func() time.Time
+}
+
+func (t *ClientCredentials) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base().RoundTrip(withBearer(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Токен мог быть отозван до истечения — берём новый и повторяем.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	t.invalidate(token)
+	fresh, err := t.Token(req.Context())
+	if err != nil || fresh == token {
+		return resp, nil
+	}
+	retry := withBearer(req, fresh)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return t.base().RoundTrip(retry)
+}
+
+// Token возвращает действующий access token, при необходимости запрашивая
+// новый у TokenURL.
+func (t *ClientCredentials) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && (t.expiry.IsZero() || t.clock().Add(expiryDelta).Before(t.expiry)) {
+		return t.token, nil
+	}
+	token, expiresIn, err := t.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expiry = time.Time{}
+	if expiresIn > 0 {
+		t.expiry = t.clock().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return t.token, nil
+}
+
+func (t *ClientCredentials) invalidate(rejected string) {
+	t.mu.Lock()
+	if t.token == rejected {
+		t.token = ""
+	}
+	t.mu.Unlock()
+}
+
+func (t *ClientCredentials) fetch(ctx context.Context) (string, int64, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(t.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", t.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("upstream: token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// RFC 6749, 2.3.1: id и секрет кодируются как form-urlencoded.
+	req.SetBasicAuth(url.QueryEscape(t.ClientID), url.QueryEscape(t.ClientSecret))
+
+	client := t.TokenClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("upstream: token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode < 400 {
+		return "", 0, fmt.Errorf("upstream: decode token response: %w", err)
+	}
+	if resp.StatusCode >= 400 || body.Error != "" {
+		return "", 0, fmt.Errorf("upstream: token endpoint: HTTP %d: %s %s",
+			resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("upstream: token endpoint: empty access_token")
+	}
+	if body.TokenType != "" && !strings.EqualFold(body.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("upstream: token endpoint: unsupported token_type %q", body.TokenType)
+	}
+	return body.AccessToken, body.ExpiresIn, nil
+}
+
+func (t *ClientCredentials) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *ClientCredentials) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// withBearer возвращает копию запроса с заголовком Authorization —
+// RoundTripper не должен менять исходный запрос.
+func withBearer(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
@@ -0,0 +1,127 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer — стенд OAuth2 token endpoint. Выдаёт tok-1, tok-2, ...
+func tokenServer(t *testing.T, issued *int32, expiresIn int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client%2Fid" || secret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"invalid_client"}`)
+			return
+		}
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "read:posts read:channels" {
+			t.Errorf("form = %v", r.Form)
+		}
+		n := atomic.AddInt32(issued, 1)
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newCC(tokenURL string) *ClientCredentials {
+	return &ClientCredentials{
+		TokenURL:     tokenURL,
+		ClientID:     "client/id",
+		ClientSecret: "s3cr3t",
+		Scopes:       []string{"read:posts", "read:channels"},
+	}
+}
+
+func TestClientCredentials_TokenCached(t *testing.T) {
+	var issued int32
+	ts := tokenServer(t, &issued, 3600)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newCC(ts.URL)}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(b) != "Bearer tok-1" {
+			t.Errorf("Authorization = %q", b)
+		}
+	}
+	if issued != 1 {
+		t.Errorf("tokens issued = %d, want 1", issued)
+	}
+}
+
+func TestClientCredentials_Expiry(t *testing.T) {
+	var issued int32
+	ts := tokenServer(t, &issued, 60)
+	cc := newCC(ts.URL)
+	now := time.Now()
+	cc.now = func() time.Time { return now }
+
+	tok, _ := cc.Token(context.Background())
+	now = now.Add(45 * time.Second) // в пределах expiryDelta до истечения
+	tok2, err := cc.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != "tok-1" || tok2 != "tok-2" {
+		t.Errorf("tokens = %q, %q", tok, tok2)
+	}
+}
+
+func TestClientCredentials_RetryOn401(t *testing.T) {
+	var issued int32
+	ts := tokenServer(t, &issued, 3600)
+	var bodies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.Header.Get("Authorization") == "Bearer tok-1" {
+			w.WriteHeader(http.StatusUnauthorized) // отозван
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newCC(ts.URL)}
+	resp, err := client.Post(api.URL, "application/json", strings.NewReader(`{"q":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || issued != 2 {
+		t.Errorf("status = %d, tokens issued = %d", resp.StatusCode, issued)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"q":1}` {
+		t.Errorf("bodies = %q", bodies)
+	}
+}
+
+func TestClientCredentials_TokenError(t *testing.T) {
+	var issued int32
+	ts := tokenServer(t, &issued, 3600)
+	cc := newCC(ts.URL)
+	cc.ClientSecret = "wrong"
+
+	_, err := cc.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Учёт использования плагина: вызовы за расчётный период по эндпоинтам,
+// начисления по pricing из integrat.yaml и комиссия платформы. Тот же
+// отчёт строит пакет metering по локальному журналу вызовов.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// CommissionRate — комиссия платформы от начислений (1%).
+const CommissionRate = 0.01
+
+// Usage — использование плагина за расчётный период (счёт владельцу).
+// Суммы в Telegram Stars.
+type Usage struct {
+	Plugin     string      `json:"plugin"`
+	Period     string      `json:"period"` // календарный месяц UTC: "2026-10"
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"` // не включительно
+	Lines      []UsageLine `json:"lines"`
+	Calls      int64       `json:"calls"`
+	Gross      int64       `json:"gross"`      // начислено пользователям
+	Commission int64       `json:"commission"` // комиссия платформы
+	Net        int64       `json:"net"`        // к выплате владельцу
+}
+
+// UsageLine — строка отчёта по одному эндпоинту.
+type UsageLine struct {
+	Endpoint      string `json:"endpoint"`
+	DataType      string `json:"data_type,omitempty"`
+	Calls         int64  `json:"calls"`
+	CachedCalls   int64  `json:"cached_calls"`
+	FreeCalls     int64  `json:"free_calls"` // покрыты free_tier
+	PlanCalls     int64  `json:"plan_calls"` // покрыты included плана
+	BillableCalls int64  `json:"billable_calls"`
+	Bytes         int64  `json:"bytes"`
+	UnitPrice     int64  `json:"unit_price"`
+	Amount        int64  `json:"amount"`
+}
+
+// Commission возвращает комиссию платформы с суммы gross (с округлением).
+func Commission(gross int64) int64 {
+	return int64(float64(gross)*CommissionRate + 0.5)
+}
+
+// GetUsage возвращает использование плагина за период "YYYY-MM".
+// Пустой period — текущий месяц.
+func (c *Client) GetUsage(pluginID int64, period string) (*Usage, error) {
+	if period != "" {
+		if _, err := time.Parse("2006-01", period); err != nil {
+			return nil, fmt.Errorf("integrat: invalid usage period %q (want YYYY-MM)", period)
+		}
+	}
+	path := fmt.Sprintf("/v1/plugins/%d/usage", pluginID)
+	if period != "" {
+		path += "?" + url.Values{"period": {period}}.Encode()
+	}
+
+	respBody, _, err := c.doRequest(context.Background(), "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var usage Usage
+	if err := json.Unmarshal(respBody, &usage); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &usage, nil
+}
@@ -0,0 +1,41 @@
+package integrat
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/plugins/3/usage" || r.URL.Query().Get("period") != "2026-10" {
+			t.Errorf("%s %s", r.Method, r.URL)
+		}
+		io.WriteString(w, `{"plugin":"channel-mcp","period":"2026-10",
+			"lines":[{"endpoint":"messages.search","calls":12,"billable_calls":10,"unit_price":10,"amount":100}],
+			"calls":12,"gross":100,"commission":1,"net":99}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	u, err := c.GetUsage(3, "2026-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Gross != 100 || u.Net != 99 || len(u.Lines) != 1 || u.Lines[0].Amount != 100 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	if _, err := c.GetUsage(3, "october"); err == nil {
+		t.Error("expected error for invalid period")
+	}
+}
+
+func TestCommission(t *testing.T) {
+	for gross, want := range map[int64]int64{0: 0, 49: 0, 50: 1, 100: 1, 1000: 10, 1049: 10, 1050: 11} {
+		if got := Commission(gross); got != want {
+			t.Errorf("Commission(%d) = %d, want %d", gross, got, want)
+		}
+	}
+}
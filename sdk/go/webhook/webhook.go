@@ -0,0 +1,241 @@
+// Пакет webhook — приём доставок вебхуков Integrat: проверка подписи и
+// разбор типизированных событий.
+//
+// Каждая доставка — POST с JSON-событием и заголовками:
+//
+//	X-Integrat-Event:             тип события (install, access_requested, ...)
+//	X-Integrat-Delivery:          ID доставки; повторы имеют тот же ID
+//	X-Integrat-Webhook-Signature: t=<unix>,v1=<hex(hmac_sha256(secret, "<t>.<body>"))>
+//
+// При смене секрета подпись может содержать несколько v1 — достаточно
+// совпадения любой.
+//
+//	http.Handle("/integrat", webhook.Handler(secret, func(ctx context.Context, e *webhook.Event) error {
+//		switch p, _ := e.Payload(); v := p.(type) {
+//		case *integrat.Installation:
+//			log.Printf("installed in chat %d", v.ChatID)
+//		}
+//		return nil
+//	}))
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+// Заголовки доставки.
+const (
+	SignatureHeader = "X-Integrat-Webhook-Signature"
+	EventHeader     = "X-Integrat-Event"
+	DeliveryHeader  = "X-Integrat-Delivery"
+)
+
+// DefaultTolerance — допустимый возраст доставки.
+const DefaultTolerance = 5 * time.Minute
+
+// maxBody — предел размера тела доставки.
+const maxBody = 1 << 20
+
+// Ошибки проверки доставки.
+var (
+	ErrSignatureMissing = errors.New("webhook: signature header missing")
+	ErrSignatureInvalid = errors.New("webhook: signature mismatch")
+	ErrTimestampSkew    = errors.New("webhook: timestamp outside tolerance")
+	ErrBodyTooLarge     = errors.New("webhook: body too large")
+	ErrInvalidEvent     = errors.New("webhook: invalid event")
+)
+
+// Event — событие вебхука.
+type Event struct {
+	ID        string          `json:"id"` // совпадает с X-Integrat-Delivery
+	Type      string          `json:"type"`
+	PluginID  int64           `json:"plugin_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ProviderDown — данные события provider_down.
+type ProviderDown struct {
+	BaseURL string    `json:"base_url"`
+	Since   time.Time `json:"since"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Payload разбирает Data по типу события:
+//
+//	install, uninstall     → *integrat.Installation
+//	access_requested       → *integrat.AccessRequest
+//	subscription_created   → *integrat.Subscription
+//	provider_down          → *ProviderDown
+//	ping                   → nil
+//
+// Для неизвестных типов возвращает Data как есть (json.RawMessage).
+func (e *Event) Payload() (any, error) {
+	var v any
+	switch e.Type {
+	case integrat.EventInstall, integrat.EventUninstall:
+		v = &integrat.Installation{}
+	case integrat.EventAccessRequested:
+		v = &integrat.AccessRequest{}
+	case integrat.EventSubscriptionCreated:
+		v = &integrat.Subscription{}
+	case integrat.EventProviderDown:
+		v = &ProviderDown{}
+	case integrat.EventPing:
+		return nil, nil
+	default:
+		return e.Data, nil
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return nil, fmt.Errorf("webhook: unmarshal %s payload: %w", e.Type, err)
+	}
+	return v, nil
+}
+
+// Sign возвращает значение заголовка подписи для тела body. Так
+// подписывает gateway; функция нужна для тестов получателя.
+func Sign(secret []byte, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify проверяет подпись и возраст доставки и разбирает событие. Тело
+// запроса читается целиком (не более 1 МБ). Заголовки X-Integrat-Delivery
+// и X-Integrat-Event, если заданы, должны совпадать с Event.ID и
+// Event.Type — иначе ErrInvalidEvent.
+func Verify(r *http.Request, secret []byte) (*Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: read body: %w", err)
+	}
+	if len(body) > maxBody {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, maxBody)
+	}
+	e, err := verify(secret, r.Header.Get(SignatureHeader), body, time.Now(), DefaultTolerance)
+	if err != nil {
+		return nil, err
+	}
+	// Заголовки не подписаны — сверяем их с подписанным телом.
+	if id := r.Header.Get(DeliveryHeader); id != "" && id != e.ID {
+		return nil, fmt.Errorf("%w: %s %q does not match event id %q", ErrInvalidEvent, DeliveryHeader, id, e.ID)
+	}
+	if typ := r.Header.Get(EventHeader); typ != "" && typ != e.Type {
+		return nil, fmt.Errorf("%w: %s %q does not match event type %q", ErrInvalidEvent, EventHeader, typ, e.Type)
+	}
+	return e, nil
+}
+
+func verify(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) (*Event, error) {
+	if header == "" {
+		return nil, ErrSignatureMissing
+	}
+
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	if ts == "" || len(sigs) == 0 {
+		return nil, ErrSignatureMissing
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+	want := mac(secret, ts, body)
+	ok := false
+	for _, sig := range sigs {
+		if hmac.Equal(sig, want) {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return nil, ErrSignatureInvalid
+	}
+	// Время проверяется после подписи: t входит в подписанную строку.
+	if skew := now.Sub(time.Unix(unix, 0)); skew > tolerance || skew < -tolerance {
+		return nil, ErrTimestampSkew
+	}
+
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return &e, nil
+}
+
+func mac(secret []byte, ts string, body []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
+
+// Handler возвращает обработчик доставок. Невалидная подпись — 401, тело
+// больше 1 МБ — 413, неразборчивое событие или расхождение с заголовками —
+// 400; ошибка fn — 500 (gateway повторит доставку с тем же ID), в ответ она
+// не попадает; успех — 204. Повторы стоит отсеивать по Event.ID.
+func Handler(secret []byte, fn func(ctx context.Context, e *Event) error) http.Handler {
+	return HandlerWithLogger(secret, nil, fn)
+}
+
+// HandlerWithLogger — Handler, который пишет ошибки fn в logger (Error).
+// nil logger — ошибки не пишутся.
+func HandlerWithLogger(secret []byte, logger *slog.Logger, fn func(ctx context.Context, e *Event) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		e, err := Verify(r, secret)
+		switch {
+		case errors.Is(err, ErrBodyTooLarge):
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, ErrInvalidEvent):
+			http.Error(w, "invalid event", http.StatusBadRequest)
+			return
+		case errors.Is(err, ErrSignatureMissing), errors.Is(err, ErrSignatureInvalid), errors.Is(err, ErrTimestampSkew):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case err != nil:
+			http.Error(w, "read body failed", http.StatusBadRequest)
+			return
+		}
+		if err := fn(r.Context(), e); err != nil {
+			if logger != nil {
+				logger.ErrorContext(r.Context(), "webhook handler failed",
+					slog.String("event", e.Type), slog.String("delivery", e.ID), slog.Any("error", err))
+			}
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
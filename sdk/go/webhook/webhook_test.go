@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	integrat "github.com/plagness/Integrat/sdk/go"
+)
+
+var secret = []byte("whsec_test")
+
+const installEvent = `{"id":"dlv_1","type":"install","plugin_id":3,"created_at":"2026-10-18T10:00:00Z",
+	"data":{"id":11,"chat_id":-100,"plugin_id":3,"plugin":"channel-mcp","installed_by":7}}`
+
+func deliver(t *testing.T, url, body, signature string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "install")
+	req.Header.Set(DeliveryHeader, "dlv_1")
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestHandler(t *testing.T) {
+	var got *integrat.Installation
+	srv := httptest.NewServer(Handler(secret, func(ctx context.Context, e *Event) error {
+		p, err := e.Payload()
+		if err != nil {
+			return err
+		}
+		got, _ = p.(*integrat.Installation)
+		return nil
+	}))
+	defer srv.Close()
+
+	resp := deliver(t, srv.URL, installEvent, Sign(secret, time.Now(), []byte(installEvent)))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got == nil || got.ChatID != -100 || got.Plugin != "channel-mcp" {
+		t.Errorf("installation = %+v", got)
+	}
+
+	for name, sig := range map[string]string{
+		"unsigned":     "",
+		"wrong secret": Sign([]byte("other"), time.Now(), []byte(installEvent)),
+		"stale":        Sign(secret, time.Now().Add(-time.Hour), []byte(installEvent)),
+	} {
+		if resp := deliver(t, srv.URL, installEvent, sig); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandler_HandlerError(t *testing.T) {
+	srv := httptest.NewServer(Handler(secret, func(ctx context.Context, e *Event) error {
+		return errors.New("db down")
+	}))
+	defer srv.Close()
+
+	resp := deliver(t, srv.URL, installEvent, Sign(secret, time.Now(), []byte(installEvent)))
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 so the delivery is retried", resp.StatusCode)
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(installEvent))
+	req.Header.Set(SignatureHeader, Sign(secret, time.Now(), []byte(installEvent)))
+	rec := httptest.NewRecorder()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	HandlerWithLogger(secret, logger, func(ctx context.Context, e *Event) error { return errors.New("db down") }).ServeHTTP(rec, req)
+	if strings.Contains(rec.Body.String(), "db down") {
+		t.Errorf("body leaks handler error: %q", rec.Body.String())
+	}
+	if !strings.Contains(logs.String(), "db down") || !strings.Contains(logs.String(), "delivery=dlv_1") {
+		t.Errorf("log = %q", logs.String())
+	}
+}
+
+func TestHandler_BadRequests(t *testing.T) {
+	srv := httptest.NewServer(Handler(secret, func(ctx context.Context, e *Event) error {
+		t.Error("handler called")
+		return nil
+	}))
+	defer srv.Close()
+
+	big := string(bytes.Repeat([]byte("x"), maxBody+1))
+	for name, tc := range map[string]struct {
+		body string
+		want int
+	}{
+		"too large": {big, http.StatusRequestEntityTooLarge},
+		"not json":  {"{", http.StatusBadRequest},
+		// deliver шлёт X-Integrat-Delivery: dlv_1 и X-Integrat-Event: install.
+		"other id":   {strings.Replace(installEvent, "dlv_1", "dlv_2", 1), http.StatusBadRequest},
+		"other type": {strings.Replace(installEvent, `"install"`, `"uninstall"`, 1), http.StatusBadRequest},
+	} {
+		resp := deliver(t, srv.URL, tc.body, Sign(secret, time.Now(), []byte(tc.body)))
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s: status = %d, want %d", name, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(installEvent)
+	now := time.Unix(1_790_000_000, 0)
+	sig := Sign(secret, now, body)
+
+	if _, err := verify(secret, sig, body, now.Add(time.Minute), DefaultTolerance); err != nil {
+		t.Errorf("valid: %v", err)
+	}
+	if _, err := verify(secret, sig, append(body, ' '), now, DefaultTolerance); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("tampered body: err = %v", err)
+	}
+	if _, err := verify(secret, sig, body, now.Add(10*time.Minute), DefaultTolerance); !errors.Is(err, ErrTimestampSkew) {
+		t.Errorf("skew: err = %v", err)
+	}
+	if _, err := verify(secret, "t=1", body, now, DefaultTolerance); !errors.Is(err, ErrSignatureMissing) {
+		t.Errorf("no v1: err = %v", err)
+	}
+
+	// Ротация секрета: одна из подписей валидна.
+	rotated := Sign([]byte("old"), now, body) + "," + strings.SplitN(sig, ",", 2)[1]
+	if _, err := verify(secret, rotated, body, now, DefaultTolerance); err != nil {
+		t.Errorf("rotated: %v", err)
+	}
+}
+
+func TestVerify_BodyLimit(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), maxBody+1)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+	if _, err := Verify(req, secret); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestEvent_Payload(t *testing.T) {
+	tests := []struct {
+		typ, data string
+		check     func(any) bool
+	}{
+		{integrat.EventAccessRequested, `{"id":4,"endpoint":"messages.search","status":"pending"}`,
+			func(v any) bool { ar, ok := v.(*integrat.AccessRequest); return ok && ar.ID == 4 }},
+		{integrat.EventSubscriptionCreated, `{"id":9,"plan":{"slug":"pro"}}`,
+			func(v any) bool { s, ok := v.(*integrat.Subscription); return ok && s.Plan.Slug == "pro" }},
+		{integrat.EventProviderDown, `{"base_url":"https://api.example.com","error":"timeout"}`,
+			func(v any) bool { pd, ok := v.(*ProviderDown); return ok && pd.Error == "timeout" }},
+		{integrat.EventPing, `{}`, func(v any) bool { return v == nil }},
+		{"future_event", `{"x":1}`, func(v any) bool { _, ok := v.(json.RawMessage); return ok }},
+	}
+	for _, tt := range tests {
+		e := &Event{Type: tt.typ, Data: []byte(tt.data)}
+		v, err := e.Payload()
+		if err != nil || !tt.check(v) {
+			t.Errorf("%s: payload = %#v, err = %v", tt.typ, v, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Вебхуки владельца плагина: подписка на события (установка, заявка на
+// доступ, подписка, недоступность провайдера) вместо опроса API. Доставки
+// подписаны HMAC — проверка и разбор событий в пакете webhook.
+package integrat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// События вебхуков.
+const (
+	EventInstall             = "install"              // плагин подключён к чату (Installation)
+	EventUninstall           = "uninstall"            // плагин отключён от чата (Installation)
+	EventAccessRequested     = "access_requested"     // новая заявка на доступ (AccessRequest)
+	EventSubscriptionCreated = "subscription_created" // оформлена подписка (Subscription)
+	EventProviderDown        = "provider_down"        // health-check провайдера не проходит
+	EventPing                = "ping"                 // проверочная доставка
+)
+
+var validEvents = map[string]bool{
+	EventInstall: true, EventUninstall: true, EventAccessRequested: true,
+	EventSubscriptionCreated: true, EventProviderDown: true, EventPing: true,
+}
+
+// Webhook — подписка плагина на события.
+type Webhook struct {
+	ID             int64      `json:"id"`
+	PluginID       int64      `json:"plugin_id"`
+	URL            string     `json:"url"`
+	Events         []string   `json:"events"`
+	Secret         string     `json:"secret,omitempty"` // секрет подписи — только в ответе CreateWebhook
+	Active         bool       `json:"active"`
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+	LastStatus     int        `json:"last_status,omitempty"` // HTTP-статус последней доставки
+	CreatedAt      string     `json:"created_at"`
+}
+
+// CreateWebhook подписывает URL на события плагина. Секрет подписи
+// возвращается только здесь.
+func (c *Client) CreateWebhook(pluginID int64, rawURL string, events []string) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("integrat: webhook url must be an absolute http(s) URL, got %q", rawURL)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("integrat: at least one webhook event is required")
+	}
+	for _, e := range events {
+		if !validEvents[e] {
+			return nil, fmt.Errorf("integrat: unknown webhook event %q", e)
+		}
+	}
+
+	body := map[string]any{"url": rawURL, "events": events}
+	respBody, _, err := c.doJSON(context.Background(), "POST", fmt.Sprintf("/v1/plugins/%d/webhooks", pluginID), body)
+	if err != nil {
+		return nil, err
+	}
+	var wh Webhook
+	if err := json.Unmarshal(respBody, &wh); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return &wh, nil
+}
+
+// ListWebhooks возвращает вебхуки плагина (без секретов).
+func (c *Client) ListWebhooks(pluginID int64) ([]Webhook, error) {
+	respBody, _, err := c.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/plugins/%d/webhooks", pluginID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []Webhook
+	if err := json.Unmarshal(respBody, &hooks); err != nil {
+		return nil, fmt.Errorf("integrat: unmarshal: %w", err)
+	}
+	return hooks, nil
+}
+
+// PingWebhook отправляет на вебхук проверочное событие ping.
+func (c *Client) PingWebhook(pluginID, webhookID int64) error {
+	_, _, err := c.doRequest(context.Background(), "POST", fmt.Sprintf("/v1/plugins/%d/webhooks/%d/ping", pluginID, webhookID), nil)
+	return err
+}
+
+// DeleteWebhook удаляет вебхук.
+func (c *Client) DeleteWebhook(pluginID, webhookID int64) error {
+	_, _, err := c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/plugins/%d/webhooks/%d", pluginID, webhookID), nil)
+	return err
+}
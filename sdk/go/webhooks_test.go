@@ -0,0 +1,81 @@
+package integrat
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/plugins/3/webhooks" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"id":5,"plugin_id":3,"url":"https://example.com/hook","events":["install"],"secret":"whsec_x","active":true}`)
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	wh, err := c.CreateWebhook(3, "https://example.com/hook", []string{EventInstall, EventAccessRequested})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["url"] != "https://example.com/hook" || len(got["events"].([]any)) != 2 {
+		t.Errorf("request = %v", got)
+	}
+	if wh.Secret != "whsec_x" || !wh.Active {
+		t.Errorf("webhook = %+v", wh)
+	}
+}
+
+func TestCreateWebhook_Validation(t *testing.T) {
+	c := NewWithURL("t", "http://127.0.0.1:0")
+	tests := []struct {
+		url    string
+		events []string
+		want   string
+	}{
+		{"/relative", []string{EventInstall}, "absolute"},
+		{"ftp://x/hook", []string{EventInstall}, "absolute"},
+		{"https://x/hook", nil, "at least one"},
+		{"https://x/hook", []string{"deploy"}, `"deploy"`},
+	}
+	for _, tt := range tests {
+		_, err := c.CreateWebhook(1, tt.url, tt.events)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("CreateWebhook(%q, %v) err = %v, want %q", tt.url, tt.events, err, tt.want)
+		}
+	}
+}
+
+func TestListPingDeleteWebhooks(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == "GET" {
+			io.WriteString(w, `[{"id":5,"url":"https://example.com/hook","events":["install"],"last_status":200}]`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithURL("t", srv.URL)
+	hooks, err := c.ListWebhooks(3)
+	if err != nil || len(hooks) != 1 || hooks[0].LastStatus != 200 {
+		t.Errorf("list = %+v, %v", hooks, err)
+	}
+	if err := c.PingWebhook(3, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteWebhook(3, 5); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET /v1/plugins/3/webhooks,POST /v1/plugins/3/webhooks/5/ping,DELETE /v1/plugins/3/webhooks/5"
+	if strings.Join(calls, ",") != want {
+		t.Errorf("calls = %v", calls)
+	}
+}